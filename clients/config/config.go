@@ -1,55 +1,126 @@
-package config
-
-import "github.com/parnurzeal/gorequest"
-
-type ClientConfig struct {
-	client       *gorequest.SuperAgent
-	baseURL      string
-	signatureKey string
-}
-
-type IClientConfig interface {
-	Client() *gorequest.SuperAgent
-	BaseURL() string
-	SignatureKey() string
-}
-
-type Option func(*ClientConfig)
-
-func NewClientConfig(options ...Option) IClientConfig {
-	clientConfig := &ClientConfig{
-		client: gorequest.New().
-			Set("Content-Type", "application/json").
-			Set("Accept", "application/json"),
-	}
-
-	for _, option := range options {
-		option(clientConfig)
-	}
-
-	return clientConfig
-}
-
-func (c *ClientConfig) Client() *gorequest.SuperAgent {
-	return c.client
-}
-
-func (c *ClientConfig) BaseURL() string {
-	return c.baseURL
-}
-
-func (c *ClientConfig) SignatureKey() string {
-	return c.signatureKey
-}
-
-func WithBaseURL(baseURL string) Option {
-	return func(c *ClientConfig) {
-		c.baseURL = baseURL
-	}
-}
-
-func WithSignatureKey(signatureKey string) Option {
-	return func(c *ClientConfig) {
-		c.signatureKey = signatureKey
-	}
-}
+package config
+
+import (
+	"field-service/common/circuitbreaker"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultTimeout                = 5 * time.Second
+	defaultMaxRetries             = 2
+	defaultRetryBackoff           = 100 * time.Millisecond
+	defaultFailureThreshold       = 5
+	defaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+type ClientConfig struct {
+	client         *http.Client
+	baseURL        string
+	signatureKey   string
+	timeout        time.Duration
+	maxRetries     int
+	retryBackoff   time.Duration
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+type IClientConfig interface {
+	Client() *http.Client
+	BaseURL() string
+	SignatureKey() string
+	Timeout() time.Duration
+	MaxRetries() int
+	RetryBackoff() time.Duration
+	CircuitBreaker() *circuitbreaker.CircuitBreaker
+}
+
+type Option func(*ClientConfig)
+
+func NewClientConfig(options ...Option) IClientConfig {
+	clientConfig := &ClientConfig{
+		client:         &http.Client{},
+		timeout:        defaultTimeout,
+		maxRetries:     defaultMaxRetries,
+		retryBackoff:   defaultRetryBackoff,
+		circuitBreaker: circuitbreaker.New(defaultFailureThreshold, defaultCircuitBreakerCooldown),
+	}
+
+	for _, option := range options {
+		option(clientConfig)
+	}
+
+	return clientConfig
+}
+
+func (c *ClientConfig) Client() *http.Client {
+	return c.client
+}
+
+func (c *ClientConfig) BaseURL() string {
+	return c.baseURL
+}
+
+func (c *ClientConfig) SignatureKey() string {
+	return c.signatureKey
+}
+
+// Timeout bounds a single attempt; the request context may end it earlier.
+func (c *ClientConfig) Timeout() time.Duration {
+	return c.timeout
+}
+
+func (c *ClientConfig) MaxRetries() int {
+	return c.maxRetries
+}
+
+func (c *ClientConfig) RetryBackoff() time.Duration {
+	return c.retryBackoff
+}
+
+func (c *ClientConfig) CircuitBreaker() *circuitbreaker.CircuitBreaker {
+	return c.circuitBreaker
+}
+
+func WithBaseURL(baseURL string) Option {
+	return func(c *ClientConfig) {
+		c.baseURL = baseURL
+	}
+}
+
+func WithSignatureKey(signatureKey string) Option {
+	return func(c *ClientConfig) {
+		c.signatureKey = signatureKey
+	}
+}
+
+// WithTimeout overrides the default attempt timeout when timeout is positive.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *ClientConfig) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// WithRetry overrides the default retries and backoff with the positive
+// values among maxRetries and backoff.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(c *ClientConfig) {
+		if maxRetries > 0 {
+			c.maxRetries = maxRetries
+		}
+		if backoff > 0 {
+			c.retryBackoff = backoff
+		}
+	}
+}
+
+// WithCircuitBreaker replaces the default breaker when both values are
+// positive.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) Option {
+	return func(c *ClientConfig) {
+		if failureThreshold > 0 && cooldown > 0 {
+			c.circuitBreaker = circuitbreaker.New(failureThreshold, cooldown)
+		}
+	}
+}
@@ -1,26 +1,95 @@
-package clients
-
-import (
-	"field-service/clients/config"
-	clients "field-service/clients/user"
-	config2 "field-service/config"
-)
-
-type ClientRegistry struct{}
-
-type IClientRegistry interface {
-	GetUser() clients.IUserClient
-}
-
-func NewClientRegistry() IClientRegistry {
-	return &ClientRegistry{}
-}
-
-func (c *ClientRegistry) GetUser() clients.IUserClient {
-	return clients.NewUserClient(
-		config.NewClientConfig(
-			config.WithBaseURL(config2.Config.InternalService.User.Host),
-			config.WithSignatureKey(config2.Config.InternalService.User.SignatureKey),
-		),
-	)
-}
+package clients
+
+import (
+	"field-service/clients/config"
+	clients "field-service/clients/user"
+	"field-service/common/circuitbreaker"
+	"field-service/common/metrics"
+	config2 "field-service/config"
+	"time"
+)
+
+type ClientRegistry struct {
+	user clients.IUserClient
+}
+
+type IClientRegistry interface {
+	GetUser() clients.IUserClient
+}
+
+// NewClientRegistry builds the clients once, as the local user client keeps
+// its signing keys between requests.
+func NewClientRegistry() IClientRegistry {
+	return &ClientRegistry{user: newUserClient()}
+}
+
+func (c *ClientRegistry) GetUser() clients.IUserClient {
+	return c.user
+}
+
+// breakerStates are the values of the circuit breaker state metric.
+var breakerStates = map[string]float64{
+	circuitbreaker.Closed:   0,
+	circuitbreaker.HalfOpen: 1,
+	circuitbreaker.Open:     2,
+}
+
+// lookupTimeout is the longest a lookup can take with every retry, each
+// waiting its longest jittered backoff, see clients.UserClient.
+func lookupTimeout(clientConfig config.IClientConfig) time.Duration {
+	timeout := clientConfig.Timeout()
+	for attempt := 1; attempt <= clientConfig.MaxRetries(); attempt++ {
+		backoff := clientConfig.RetryBackoff() << (attempt - 1)
+		timeout += clientConfig.Timeout() + backoff/2 + backoff
+	}
+	return timeout
+}
+
+func newUserClient() clients.IUserClient {
+	userConfig := config2.Config.InternalService.User
+	clientConfig := config.NewClientConfig(
+		config.WithBaseURL(userConfig.Host),
+		config.WithSignatureKey(userConfig.SignatureKey),
+		config.WithTimeout(time.Duration(userConfig.TimeoutSecond)*time.Second),
+		config.WithRetry(
+			userConfig.Retry.MaxRetries,
+			time.Duration(userConfig.Retry.BackoffMillisecond)*time.Millisecond,
+		),
+		config.WithCircuitBreaker(
+			userConfig.CircuitBreaker.FailureThreshold,
+			time.Duration(userConfig.CircuitBreaker.CooldownSecond)*time.Second,
+		),
+	)
+	metrics.RegisterCircuitBreaker(func() float64 {
+		return breakerStates[clientConfig.CircuitBreaker().State()]
+	})
+
+	remote := clients.NewUserClient(clientConfig)
+	if userConfig.Cache.Enabled {
+		cache := clients.NewCachedUserClient(
+			remote,
+			time.Duration(userConfig.Cache.TTLSecond)*time.Second,
+			lookupTimeout(clientConfig),
+			userConfig.Cache.Size,
+		)
+		metrics.RegisterUserCache(
+			func() float64 { return float64(cache.Stats().Hits) },
+			func() float64 { return float64(cache.Stats().Misses) },
+		)
+		remote = cache
+	}
+
+	if !userConfig.JWT.Enabled {
+		return remote
+	}
+
+	var fallback clients.IUserClient
+	if userConfig.JWT.Fallback {
+		fallback = remote
+	}
+	local, err := clients.NewLocalUserClient(userConfig.JWT, fallback)
+	if err != nil {
+		panic(err)
+	}
+	return local
+}
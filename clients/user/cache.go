@@ -0,0 +1,113 @@
+package clients
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedUserClient remembers the users returned by client for ttl, keyed by a
+// hash of the token so tokens are never kept in memory as is. Concurrent
+// lookups of the same token share a single call, which is bounded by timeout
+// rather than by the context of whichever request started it. Failed lookups
+// are not cached.
+type CachedUserClient struct {
+	client  IUserClient
+	ttl     time.Duration
+	timeout time.Duration
+	cache   *lru.Cache
+	group   singleflight.Group
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+type ICachedUserClient interface {
+	IUserClient
+	Stats() CacheStats
+}
+
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type cachedUser struct {
+	user      *UserData
+	expiresAt time.Time
+}
+
+func NewCachedUserClient(client IUserClient, ttl, timeout time.Duration, size int) ICachedUserClient {
+	// lru.New only fails for a non-positive size.
+	cache, err := lru.New(max(size, 1))
+	if err != nil {
+		panic(err)
+	}
+
+	return &CachedUserClient{
+		client:  client,
+		ttl:     ttl,
+		timeout: timeout,
+		cache:   cache,
+	}
+}
+
+func (c *CachedUserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
+	token, _ := ctx.Value(constants.Token).(string)
+	if token == "" {
+		return nil, errConstant.ErrUnauthorized
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(hash[:])
+	if value, ok := c.cache.Get(key); ok {
+		entry := value.(cachedUser)
+		if time.Now().Before(entry.expiresAt) {
+			c.hits.Add(1)
+			return entry.user, nil
+		}
+		c.cache.Remove(key)
+	}
+	c.misses.Add(1)
+
+	result := c.group.DoChan(key, func() (interface{}, error) {
+		// The lookup is shared, so one caller giving up must not fail the
+		// others; each of them stops waiting on its own context instead.
+		sharedCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+		defer cancel()
+
+		user, err := c.client.GetUserByToken(sharedCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		c.cache.Add(key, cachedUser{
+			user:      user,
+			expiresAt: time.Now().Add(c.ttl),
+		})
+		return user, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case shared := <-result:
+		if shared.Err != nil {
+			return nil, shared.Err
+		}
+		return shared.Val.(*UserData), nil
+	}
+}
+
+func (c *CachedUserClient) Stats() CacheStats {
+	return CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
@@ -0,0 +1,204 @@
+package clients
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// jwksRetryInterval keeps tokens with an unknown key ID from refetching the
+// key set on every request.
+const jwksRetryInterval = 30 * time.Second
+
+var errKeyNotFound = errors.New("signing key not found")
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// keySet holds the RSA keys of a JWKS document read from a file or a URL. A
+// URL is refetched once the keys are older than refresh, or when a token is
+// signed with a key ID the set does not know yet. Concurrent requests share
+// one fetch, which runs without holding the mutex.
+type keySet struct {
+	file      string
+	url       string
+	refresh   time.Duration
+	client    *http.Client
+	group     singleflight.Group
+	mutex     sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func newKeySet(file, url string, refresh time.Duration) *keySet {
+	return &keySet{
+		file:    file,
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (k *keySet) key(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	k.mutex.RLock()
+	keys, fetchedAt := k.keys, k.fetchedAt
+	k.mutex.RUnlock()
+
+	stale := keys == nil || (k.url != "" && k.refresh > 0 && time.Since(fetchedAt) > k.refresh)
+	if !stale {
+		if key, ok := findKey(keys, keyID); ok {
+			return key, nil
+		}
+		if k.url == "" || time.Since(fetchedAt) < jwksRetryInterval {
+			return nil, errKeyNotFound
+		}
+	}
+
+	fetched, err := k.reload(ctx)
+	if err != nil {
+		if key, ok := findKey(keys, keyID); ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	key, ok := findKey(fetched, keyID)
+	if !ok {
+		return nil, errKeyNotFound
+	}
+	return key, nil
+}
+
+// reload loads the keys once for every request waiting on them. The load
+// outlives a canceled request, bounded by the client timeout, so the others
+// still get its result; each request stops waiting when its own ctx is done.
+func (k *keySet) reload(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	result := k.group.DoChan("jwks", func() (interface{}, error) {
+		keys, err := k.load(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		k.mutex.Lock()
+		k.keys = keys
+		k.fetchedAt = time.Now()
+		k.mutex.Unlock()
+		return keys, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case shared := <-result:
+		if shared.Err != nil {
+			return nil, shared.Err
+		}
+		return shared.Val.(map[string]*rsa.PublicKey), nil
+	}
+}
+
+// findKey looks a key up by ID. Tokens without a key ID are accepted when the
+// set holds a single key.
+func findKey(keys map[string]*rsa.PublicKey, keyID string) (*rsa.PublicKey, bool) {
+	if keyID == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	key, ok := keys[keyID]
+	return key, ok
+}
+
+func (k *keySet) load(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if k.file != "" {
+		data, err = os.ReadFile(k.file)
+	} else {
+		data, err = k.fetch(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err = json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(document.Keys))
+	for _, webKey := range document.Keys {
+		if webKey.KeyType != "RSA" || (webKey.Use != "" && webKey.Use != "sig") {
+			continue
+		}
+
+		key, err := webKey.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: %w", webKey.KeyID, err)
+		}
+		keys[webKey.KeyID] = key
+	}
+	return keys, nil
+}
+
+func (k *keySet) fetch(ctx context.Context) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := k.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks response: %s", response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
+
+func (j jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil {
+		return nil, err
+	}
+
+	e := new(big.Int).SetBytes(exponent)
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(e.Int64()),
+	}, nil
+}
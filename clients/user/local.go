@@ -0,0 +1,145 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	config2 "field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+
+	// tokenLeeway tolerates clock skew between us and the user service.
+	tokenLeeway = 30 * time.Second
+)
+
+// LocalUserClient reads the user from the claims of a bearer token it
+// verifies itself. Tokens it cannot verify are handed to fallback, when set.
+type LocalUserClient struct {
+	parser    *jwt.Parser
+	keyFunc   func(context.Context) jwt.Keyfunc
+	userClaim string
+	fallback  IUserClient
+}
+
+// NewLocalUserClient fails on a configuration that could never verify a
+// token, rather than rejecting every request later.
+func NewLocalUserClient(jwtConfig config2.UserJWT, fallback IUserClient) (IUserClient, error) {
+	keyFunc, err := newKeyFunc(jwtConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwtConfig.Algorithm}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(tokenLeeway),
+	}
+	if jwtConfig.Issuer != "" {
+		options = append(options, jwt.WithIssuer(jwtConfig.Issuer))
+	}
+	if jwtConfig.Audience != "" {
+		options = append(options, jwt.WithAudience(jwtConfig.Audience))
+	}
+
+	return &LocalUserClient{
+		parser:    jwt.NewParser(options...),
+		keyFunc:   keyFunc,
+		userClaim: jwtConfig.UserClaim,
+		fallback:  fallback,
+	}, nil
+}
+
+// newKeyFunc returns the key lookup of a request. RS256 keys may have to be
+// fetched, which the request context bounds.
+func newKeyFunc(jwtConfig config2.UserJWT) (func(context.Context) jwt.Keyfunc, error) {
+	switch jwtConfig.Algorithm {
+	case HS256:
+		if jwtConfig.Secret == "" {
+			return nil, errors.New("jwt: HS256 needs a secret")
+		}
+
+		secret := []byte(jwtConfig.Secret)
+		return func(context.Context) jwt.Keyfunc {
+			return func(*jwt.Token) (interface{}, error) {
+				return secret, nil
+			}
+		}, nil
+	case RS256:
+		if jwtConfig.JWKSFile == "" && jwtConfig.JWKSURL == "" {
+			return nil, errors.New("jwt: RS256 needs a jwksFile or jwksURL")
+		}
+
+		keys := newKeySet(
+			jwtConfig.JWKSFile,
+			jwtConfig.JWKSURL,
+			time.Duration(jwtConfig.JWKSRefreshSecond)*time.Second,
+		)
+		return func(ctx context.Context) jwt.Keyfunc {
+			return func(token *jwt.Token) (interface{}, error) {
+				keyID, _ := token.Header["kid"].(string)
+				return keys.key(ctx, keyID)
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %q", jwtConfig.Algorithm)
+	}
+}
+
+func (l *LocalUserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
+	tokenString, _ := ctx.Value(constants.Token).(string)
+	if tokenString == "" {
+		return nil, errConstant.ErrUnauthorized
+	}
+
+	user, err := l.verify(ctx, tokenString)
+	if err == nil {
+		return user, nil
+	}
+
+	if l.fallback == nil {
+		return nil, err
+	}
+
+	logrus.WithContext(ctx).Warnf("local token verification failed, asking user service: %v", err)
+	return l.fallback.GetUserByToken(ctx)
+}
+
+func (l *LocalUserClient) verify(ctx context.Context, tokenString string) (*UserData, error) {
+	claims := jwt.MapClaims{}
+	_, err := l.parser.ParseWithClaims(tokenString, claims, l.keyFunc(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrInvalidToken, err)
+	}
+
+	var userClaims interface{} = map[string]interface{}(claims)
+	if l.userClaim != "" {
+		userClaims = claims[l.userClaim]
+	}
+
+	data, err := json.Marshal(userClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	var user UserData
+	err = json.Unmarshal(data, &user)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrInvalidToken, err)
+	}
+
+	if user.UUID == uuid.Nil || user.Role == "" {
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrInvalidToken, errors.New("missing uuid or role claim"))
+	}
+	return &user, nil
+}
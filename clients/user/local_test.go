@@ -0,0 +1,341 @@
+package clients
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	config2 "field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var testUserUUID = uuid.MustParse("5b0e7c7e-8f4a-4d54-9a53-1f4f0b7f2a10")
+
+// stubUserClient stands in for the user service behind the local client.
+type stubUserClient struct {
+	calls int
+}
+
+func (s *stubUserClient) GetUserByToken(context.Context) (*UserData, error) {
+	s.calls++
+	return &UserData{UUID: testUserUUID, Role: "fallback"}, nil
+}
+
+func tokenContext(token string) context.Context {
+	return context.WithValue(context.Background(), constants.Token, token)
+}
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// userClaims returns valid claims for the "user" claim object; each case
+// changes what it tests.
+func userClaims(change func(jwt.MapClaims)) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss": "user-service",
+		"aud": "field-service",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"user": map[string]interface{}{
+			"uuid": testUserUUID.String(),
+			"role": "customer",
+		},
+	}
+	if change != nil {
+		change(claims)
+	}
+	return claims
+}
+
+func TestLocalUserClientHS256(t *testing.T) {
+	jwtConfig := config2.UserJWT{
+		Algorithm: HS256,
+		Secret:    "secret",
+		Issuer:    "user-service",
+		Audience:  "field-service",
+		UserClaim: "user",
+	}
+
+	tests := []struct {
+		name    string
+		config  func(*config2.UserJWT)
+		token   func(t *testing.T) string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			token: func(t *testing.T) string { return signHS256(t, "secret", userClaims(nil)) },
+			want:  "customer",
+		},
+		{
+			name: "top-level claims",
+			config: func(c *config2.UserJWT) {
+				c.UserClaim = ""
+			},
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					claims["uuid"] = testUserUUID.String()
+					claims["role"] = "admin"
+				}))
+			},
+			want: "admin",
+		},
+		{
+			name: "within leeway",
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					claims["exp"] = time.Now().Add(-tokenLeeway / 2).Unix()
+				}))
+			},
+			want: "customer",
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no expiry",
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					delete(claims, "exp")
+				}))
+			},
+			wantErr: true,
+		},
+		{
+			name:    "wrong secret",
+			token:   func(t *testing.T) string { return signHS256(t, "other", userClaims(nil)) },
+			wantErr: true,
+		},
+		{
+			name: "other algorithm",
+			token: func(t *testing.T) string {
+				token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, userClaims(nil)).SignedString([]byte("secret"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsigned",
+			token: func(t *testing.T) string {
+				token, err := jwt.NewWithClaims(jwt.SigningMethodNone, userClaims(nil)).
+					SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					claims["iss"] = "someone-else"
+				}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					claims["aud"] = "order-service"
+				}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing role",
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					claims["user"] = map[string]interface{}{"uuid": testUserUUID.String()}
+				}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing user claim",
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					delete(claims, "user")
+				}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid uuid",
+			token: func(t *testing.T) string {
+				return signHS256(t, "secret", userClaims(func(claims jwt.MapClaims) {
+					claims["user"] = map[string]interface{}{"uuid": "nope", "role": "customer"}
+				}))
+			},
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			token:   func(*testing.T) string { return "not.a.token" },
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			caseConfig := jwtConfig
+			if test.config != nil {
+				test.config(&caseConfig)
+			}
+			client, err := NewLocalUserClient(caseConfig, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			user, err := client.GetUserByToken(tokenContext(test.token(t)))
+			if test.wantErr {
+				if !errors.Is(err, errConstant.ErrInvalidToken) {
+					t.Fatalf("got %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.UUID != testUserUUID || user.Role != test.want {
+				t.Errorf("got user %s with role %q, want %s with role %q", user.UUID, user.Role, testUserUUID, test.want)
+			}
+		})
+	}
+}
+
+func TestLocalUserClientFallback(t *testing.T) {
+	fallback := &stubUserClient{}
+	client, err := NewLocalUserClient(config2.UserJWT{Algorithm: HS256, Secret: "secret", UserClaim: "user"}, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := client.GetUserByToken(tokenContext(signHS256(t, "secret", userClaims(nil))))
+	if err != nil || user.Role != "customer" || fallback.calls != 0 {
+		t.Fatalf("got %+v, %v after %d fallback calls, want the local user", user, err, fallback.calls)
+	}
+
+	user, err = client.GetUserByToken(tokenContext(signHS256(t, "other", userClaims(nil))))
+	if err != nil || user.Role != "fallback" || fallback.calls != 1 {
+		t.Fatalf("got %+v, %v after %d fallback calls, want the fallback user", user, err, fallback.calls)
+	}
+
+	_, err = client.GetUserByToken(context.Background())
+	if !errors.Is(err, errConstant.ErrUnauthorized) || fallback.calls != 1 {
+		t.Fatalf("got %v after %d fallback calls, want ErrUnauthorized without a token", err, fallback.calls)
+	}
+}
+
+func TestLocalUserClientRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, err := json.Marshal(map[string]interface{}{
+		"keys": []jsonWebKey{{
+			KeyType: "RSA",
+			KeyID:   "key-1",
+			Use:     "sig",
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(jwksFile, document, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewLocalUserClient(config2.UserJWT{Algorithm: RS256, JWKSFile: jwksFile, UserClaim: "user"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keyID   string
+		key     *rsa.PrivateKey
+		wantErr bool
+	}{
+		{name: "known key", keyID: "key-1", key: key},
+		{name: "single key without id", key: key},
+		{name: "unknown key id", keyID: "key-2", key: key, wantErr: true},
+		{name: "other key", keyID: "key-1", key: otherKey, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, userClaims(nil))
+			if test.keyID != "" {
+				token.Header["kid"] = test.keyID
+			}
+			signed, err := token.SignedString(test.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = client.GetUserByToken(tokenContext(signed))
+			if (err != nil) != test.wantErr {
+				t.Errorf("got %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLocalUserClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  config2.UserJWT
+		wantErr bool
+	}{
+		{name: "HS256", config: config2.UserJWT{Algorithm: HS256, Secret: "secret"}},
+		{name: "HS256 without secret", config: config2.UserJWT{Algorithm: HS256}, wantErr: true},
+		{name: "RS256 with url", config: config2.UserJWT{Algorithm: RS256, JWKSURL: "http://localhost/jwks"}},
+		{name: "RS256 without keys", config: config2.UserJWT{Algorithm: RS256}, wantErr: true},
+		{name: "unsupported algorithm", config: config2.UserJWT{Algorithm: "none", Secret: "secret"}, wantErr: true},
+		{name: "no algorithm", config: config2.UserJWT{Secret: "secret"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewLocalUserClient(test.config, nil)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
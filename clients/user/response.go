@@ -1,19 +1,19 @@
-package clients
-
-import "github.com/google/uuid"
-
-type UserResponse struct {
-	Code    int      `json:"code"`
-	Status  string   `json:"status"`
-	Message string   `json:"message"`
-	Data    UserData `json:"data"`
-}
-
-type UserData struct {
-	UUID        uuid.UUID `json:"uuid"`
-	Name        string    `json:"name"`
-	Email       string    `json:"email"`
-	Role        string    `json:"role"`
-	Usernamee   string    `json:"username"`
-	PhoneNumber string    `json:"phone_number"`
-}
+package clients
+
+import "github.com/google/uuid"
+
+type UserResponse struct {
+	Code    int      `json:"code"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Data    UserData `json:"data"`
+}
+
+type UserData struct {
+	UUID        uuid.UUID `json:"uuid"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	Usernamee   string    `json:"username"`
+	PhoneNumber string    `json:"phone_number"`
+}
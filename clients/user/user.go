@@ -1,56 +1,161 @@
-package clients
-
-import (
-	"context"
-	"field-service/clients/config"
-	"field-service/common/util"
-	config2 "field-service/config"
-	"field-service/constants"
-	"fmt"
-	"net/http"
-	"time"
-)
-
-type UserClient struct {
-	client config.IClientConfig
-}
-
-type IUserClient interface {
-	GetUserByToken(context.Context) (*UserData, error)
-}
-
-func NewUserClient(client config.IClientConfig) IUserClient {
-	return &UserClient{client: client}
-}
-
-func (u *UserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
-	unixTime := time.Now().Unix()
-	generateAPIKey := fmt.Sprintf("%s:%s:%d",
-		config2.Config.AppName,
-		u.client.SignatureKey(),
-		unixTime,
-	)
-	apiKey := util.GenerateSHA256(generateAPIKey)
-	token := ctx.Value(constants.Token).(string)
-	bearerToken := fmt.Sprintf("Bearer %s", token)
-
-	var response UserResponse
-	request := u.client.Client().Clone().
-		Set(constants.Authorization, bearerToken).
-		Set(constants.XApiKey, apiKey).
-		Set(constants.XServiceName, config2.Config.AppName).
-		Set(constants.XRequestAt, fmt.Sprintf("%d", unixTime)).
-		Get(fmt.Sprintf("%s/api/v1/auth/user", u.client.BaseURL()))
-
-	resp, _, errs := request.EndStruct(&response)
-	if len(errs) > 0 {
-		return nil, errs[0]
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("user response: %s", response.Message)
-	}
-
-	return &response.Data, nil
-
-}
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"field-service/clients/config"
+	"field-service/common/metrics"
+	"field-service/common/trace"
+	"field-service/common/util"
+	config2 "field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+type UserClient struct {
+	client config.IClientConfig
+}
+
+type IUserClient interface {
+	GetUserByToken(context.Context) (*UserData, error)
+}
+
+func NewUserClient(client config.IClientConfig) IUserClient {
+	return &UserClient{client: client}
+}
+
+// GetUserByToken returns errConstant.ErrUnauthorized when the user service
+// rejects the token and errConstant.ErrUserServiceUnavailable when it cannot
+// answer. Being a GET, the lookup is retried with jittered backoff; the
+// circuit breaker fails fast while the user service is down.
+func (u *UserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
+	token, _ := ctx.Value(constants.Token).(string)
+	if token == "" {
+		return nil, errConstant.ErrUnauthorized
+	}
+
+	breaker := u.client.CircuitBreaker()
+	if err := breaker.Allow(); err != nil {
+		metrics.UserClientErrors.WithLabelValues("circuit_open").Inc()
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrUserServiceUnavailable, err)
+	}
+
+	var err error
+	for attempt := 0; attempt <= u.client.MaxRetries(); attempt++ {
+		if attempt > 0 {
+			err = u.wait(ctx, attempt)
+			if err != nil {
+				break
+			}
+		}
+
+		var user *UserData
+		user, err = u.getUser(ctx, token)
+		if !errors.Is(err, errConstant.ErrUserServiceUnavailable) {
+			// The user service answered, even if only to reject the token.
+			breaker.Success()
+			return user, err
+		}
+	}
+
+	if ctx.Err() != nil {
+		breaker.Release()
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrUserServiceUnavailable, ctx.Err())
+	}
+
+	breaker.Failure()
+	return nil, err
+}
+
+// wait sleeps before a retry: the backoff doubles per attempt and is jittered
+// between half and one and a half times its value.
+func (u *UserClient) wait(ctx context.Context, attempt int) error {
+	backoff := u.client.RetryBackoff() << (attempt - 1)
+	backoff = backoff/2 + rand.N(backoff+1)
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// getUser makes a single call to the user service and records it in the user
+// client metrics.
+func (u *UserClient) getUser(ctx context.Context, token string) (*UserData, error) {
+	start := time.Now()
+	user, err := u.requestUser(ctx, token)
+
+	result := "success"
+	switch {
+	case errors.Is(err, errConstant.ErrUserServiceUnavailable):
+		result = "unavailable"
+	case err != nil:
+		result = "unauthorized"
+	}
+	metrics.UserClientRequestDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.UserClientErrors.WithLabelValues(result).Inc()
+	}
+	return user, err
+}
+
+func (u *UserClient) requestUser(ctx context.Context, token string) (*UserData, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.client.Timeout())
+	defer cancel()
+
+	unixTime := time.Now().Unix()
+	generateAPIKey := fmt.Sprintf("%s:%s:%d",
+		config2.Config.AppName,
+		u.client.SignatureKey(),
+		unixTime,
+	)
+	apiKey := util.GenerateSHA256(generateAPIKey)
+	bearerToken := fmt.Sprintf("Bearer %s", token)
+
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/api/v1/auth/user", u.client.BaseURL()),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set(constants.Authorization, bearerToken)
+	request.Header.Set(constants.XApiKey, apiKey)
+	request.Header.Set(constants.XServiceName, config2.Config.AppName)
+	request.Header.Set(constants.XRequestAt, fmt.Sprintf("%d", unixTime))
+	trace.Inject(ctx, request.Header)
+
+	resp, err := u.client.Client().Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrUserServiceUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var response UserResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("%w: user response: %s", errConstant.ErrUserServiceUnavailable, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: user response: %s", errConstant.ErrUnauthorized, response.Message)
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrUserServiceUnavailable, err)
+	}
+
+	return &response.Data, nil
+}
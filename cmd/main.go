@@ -1,119 +1,238 @@
-package cmd
-
-import (
-	"field-service/clients"
-	"field-service/common/response"
-	"field-service/common/s3"
-	"field-service/config"
-	"field-service/constants"
-	"field-service/controllers"
-	"field-service/domain/models"
-	"field-service/middlewares"
-	"field-service/repositories"
-	"field-service/routes"
-	"field-service/services"
-	"fmt"
-	"net/http"
-	"time"
-
-	"github.com/didip/tollbooth"
-	"github.com/didip/tollbooth/limiter"
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-	"github.com/spf13/cobra"
-)
-
-var command = &cobra.Command{
-	Use:   "serve",
-	Short: "Start the server",
-	Run: func(c *cobra.Command, args []string) {
-		_ = godotenv.Load()
-		config.Init()
-		db, err := config.InitDatabase()
-		if err != nil {
-			panic(err)
-		}
-
-		loc, err := time.LoadLocation("Asia/Jakarta")
-		if err != nil {
-			panic(err)
-		}
-		time.Local = loc
-
-		err = db.AutoMigrate(
-			&models.Field{},
-			&models.FieldSchedule{},
-			&models.Time{},
-		)
-		if err != nil {
-			panic(err)
-		}
-
-		s3Client := initS3()
-		client := clients.NewClientRegistry()
-		repository := repositories.NewRepositoryRegistry(db)
-		service := services.NewServiceRegistry(repository, s3Client)
-		controller := controllers.NewControllerRegistry(service)
-
-		router := gin.Default()
-		router.Use(middlewares.HandlePanic())
-		router.NoRoute(func(c *gin.Context) {
-			c.JSON(http.StatusNotFound, response.Response{
-				Status:  constants.Error,
-				Message: fmt.Sprintf("Path %s", http.StatusText(http.StatusNotFound)),
-			})
-		})
-		router.GET("/", func(c *gin.Context) {
-			c.JSON(http.StatusOK, response.Response{
-				Status:  constants.Success,
-				Message: "Welcome to Field Service",
-			})
-		})
-		router.Use(func(c *gin.Context) {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-			c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH")
-			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-service-name, x-request-at, x-api-key")
-			if c.Request.Method == "OPTIONS" {
-				c.AbortWithStatus(204)
-				return
-			}
-			c.Next()
-		})
-
-		lmt := tollbooth.NewLimiter(
-			config.Config.RateLimiterMaxRequest,
-			&limiter.ExpirableOptions{
-				DefaultExpirationTTL: time.Duration(config.Config.RateLimiterTimeSecond) * time.Second,
-			})
-		router.Use(middlewares.RateLimiter(lmt))
-
-		group := router.Group("/api/v1")
-		route := routes.NewRouteRegistry(controller, group, client)
-		route.Serve()
-
-		port := fmt.Sprintf(":%d", config.Config.Port)
-		router.Run(port)
-	},
-}
-
-func Run() {
-	err := command.Execute()
-	if err != nil {
-		panic(err)
-	}
-}
-
-func initS3() s3.IS3Client {
-	// Log the configuration values for debugging (remove in production)
-	fmt.Println("S3 Region:", config.Config.S3Region)
-	fmt.Println("S3 Bucket:", config.Config.S3BucketName)
-
-	s3Client := s3.NewS3Client(
-		config.Config.S3AccessKeyID,
-		config.Config.S3SecretAccessKey,
-		config.Config.S3Region,
-		config.Config.S3BucketName,
-		// Consider adding an additional parameter for signature version if your implementation supports it
-	)
-	return s3Client
-}
+package cmd
+
+import (
+	"field-service/clients"
+	"field-service/common/gcs"
+	"field-service/common/local"
+	"field-service/common/logger"
+	"field-service/common/metrics"
+	"field-service/common/ratelimit"
+	"field-service/common/response"
+	"field-service/common/s3"
+	"field-service/common/storage"
+	"field-service/config"
+	"field-service/constants"
+	"field-service/controllers"
+	"field-service/domain/models"
+	"field-service/middlewares"
+	"field-service/repositories"
+	fieldRepository "field-service/repositories/field"
+	"field-service/routes"
+	"field-service/services"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// availableSlotsDays is how far ahead the available slots metric looks.
+	availableSlotsDays = 7
+
+	// availableSlotsRefresh is how often the available slots metric is
+	// counted again.
+	availableSlotsRefresh = time.Minute
+
+	defaultMetricsPort = 9102
+)
+
+var command = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the server",
+	Run: func(c *cobra.Command, args []string) {
+		_ = godotenv.Load()
+		config.Init()
+		logger.Init(config.Config.Log.Level, config.Config.Log.Format)
+		db, err := config.InitDatabase()
+		if err != nil {
+			panic(err)
+		}
+
+		loc, err := time.LoadLocation("Asia/Jakarta")
+		if err != nil {
+			panic(err)
+		}
+		time.Local = loc
+
+		err = repositories.BackfillCreatedAt(db)
+		if err != nil {
+			panic(err)
+		}
+
+		err = db.AutoMigrate(
+			&models.Field{},
+			&models.FieldSchedule{},
+			&models.Time{},
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		err = fieldRepository.Migrate(db)
+		if err != nil {
+			panic(err)
+		}
+
+		fileStorage := initStorage()
+		client := clients.NewClientRegistry()
+		repository := repositories.NewRepositoryRegistry(db)
+		service := services.NewServiceRegistry(repository, fileStorage)
+		controller := controllers.NewControllerRegistry(service)
+		metrics.RegisterAvailableSlots(availableSlotsDays, availableSlotsRefresh, service.GetFieldSchedule().CountAvailableSlots)
+
+		router := gin.New()
+		err = router.SetTrustedProxies(config.Config.TrustedProxies)
+		if err != nil {
+			panic(err)
+		}
+
+		rateLimitStore := initRateLimitStore()
+		router.Use(middlewares.CORS())
+		router.Use(middlewares.Logger())
+		router.Use(middlewares.Metrics())
+		router.Use(middlewares.HandlePanic())
+		router.Use(middlewares.IPRateLimiter(rateLimitStore))
+		router.NoRoute(func(c *gin.Context) {
+			c.JSON(http.StatusNotFound, response.Response{
+				Status:    constants.Error,
+				Message:   fmt.Sprintf("Path %s", http.StatusText(http.StatusNotFound)),
+				RequestID: c.GetString(constants.RequestID),
+			})
+		})
+		router.GET("/", func(c *gin.Context) {
+			c.JSON(http.StatusOK, response.Response{
+				Status:  constants.Success,
+				Message: "Welcome to Field Service",
+			})
+		})
+
+		if localClient, ok := fileStorage.(*local.LocalClient); ok {
+			static := router.Group(config.Config.Storage.Local.Route)
+			if config.Config.Storage.URLMode == storage.URLSigned {
+				static.Use(localClient.VerifySignature())
+			}
+			static.Static("/", config.Config.Storage.Local.Path)
+			router.PUT(config.Config.Storage.Local.Route+"/*filepath", localClient.UploadHandler())
+		}
+
+		group := router.Group("/api/v1")
+		route := routes.NewRouteRegistry(controller, router, group, client, rateLimitStore)
+		err = route.Serve()
+		if err != nil {
+			panic(err)
+		}
+
+		if config.Config.Metrics.Disable {
+			logrus.Warn("metrics.disable is set, /metrics is not served")
+		} else {
+			err = serveMetrics(config.Config.Metrics.Port)
+			if err != nil {
+				logrus.Errorf("failed to serve metrics, /metrics is not served: %v", err)
+			}
+		}
+
+		port := fmt.Sprintf(":%d", config.Config.Port)
+		router.Run(port)
+	},
+}
+
+func Run() {
+	err := command.Execute()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// serveMetrics serves /metrics on an internal port of its own, so the API
+// port does not expose them. It listens before returning, so a port in use is
+// reported at startup.
+func serveMetrics(port int) error {
+	if port == 0 {
+		port = defaultMetricsPort
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		err := http.Serve(listener, mux)
+		if err != nil {
+			logrus.Errorf("stopped serving metrics: %v", err)
+		}
+	}()
+
+	logrus.Infof("serving metrics on :%d/metrics", port)
+	return nil
+}
+
+func initStorage() storage.IStorage {
+	storageConfig := config.Config.Storage
+	switch storageConfig.Driver {
+	case "", storage.S3:
+		return s3.NewS3Client(
+			storageConfig.S3.AccessKeyID,
+			storageConfig.S3.SecretAccessKey,
+			storageConfig.S3.Region,
+			storageConfig.S3.BucketName,
+			s3.WithEndpoint(storageConfig.S3.Endpoint),
+			s3.WithForcePathStyle(storageConfig.S3.ForcePathStyle),
+			s3.WithPublicBaseURL(storageConfig.S3.PublicBaseURL),
+		)
+	case storage.GCS:
+		return gcs.NewGCSClient(gcs.ServiceAccountKeyJSON{
+			Type:                    storageConfig.GCS.Type,
+			ProjectID:               storageConfig.GCS.ProjectID,
+			PrivateKeyID:            storageConfig.GCS.PrivateKeyID,
+			PrivateKey:              storageConfig.GCS.PrivateKey,
+			ClientEmail:             storageConfig.GCS.ClientEmail,
+			ClientID:                storageConfig.GCS.ClientID,
+			AuthURI:                 storageConfig.GCS.AuthURI,
+			TokenURI:                storageConfig.GCS.TokenURI,
+			AuthProviderX509CertURL: storageConfig.GCS.AuthProviderX509CertURL,
+			ClientX509CertURL:       storageConfig.GCS.ClientX509CertURL,
+			UniverseDomain:          storageConfig.GCS.UniverseDomain,
+		}, storageConfig.GCS.BucketName)
+	case storage.Local:
+		// The key signs the presigned uploads the local driver always
+		// accepts, and the image URLs in signed mode. Without one anybody
+		// could forge both.
+		if storageConfig.Local.SignatureKey == "" {
+			panic("storage.local.signatureKey is required by the local storage driver")
+		}
+		return local.NewLocalClient(
+			storageConfig.Local.Path,
+			storageConfig.Local.BaseURL,
+			storageConfig.Local.SignatureKey,
+		)
+	default:
+		panic(fmt.Errorf("unknown storage driver %q", storageConfig.Driver))
+	}
+}
+
+func initRateLimitStore() ratelimit.Store {
+	rateLimitConfig := config.Config.RateLimit
+	switch rateLimitConfig.Store {
+	case "", ratelimit.Memory:
+		return ratelimit.NewMemoryStore()
+	case ratelimit.Redis:
+		client := redis.NewClient(&redis.Options{
+			Addr:     rateLimitConfig.Redis.Address,
+			Password: rateLimitConfig.Redis.Password,
+			DB:       rateLimitConfig.Redis.DB,
+		})
+		return ratelimit.NewRedisStore(client, rateLimitConfig.Redis.Prefix)
+	default:
+		panic(fmt.Errorf("unknown rate limit store %q", rateLimitConfig.Store))
+	}
+}
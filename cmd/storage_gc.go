@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"field-service/common/logger"
+	"field-service/config"
+	"field-service/domain/dto"
+	"field-service/repositories"
+	"field-service/services"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	storageGCDryRun      bool
+	storageGCForce       bool
+	storageGCGracePeriod time.Duration
+)
+
+var storageGCCommand = &cobra.Command{
+	Use:   "storage-gc",
+	Short: "Delete stored images no field references anymore",
+	Run: func(c *cobra.Command, args []string) {
+		_ = godotenv.Load()
+		config.Init()
+		logger.Init(config.Config.Log.Level, config.Config.Log.Format)
+		db, err := config.InitDatabase()
+		if err != nil {
+			panic(err)
+		}
+
+		repository := repositories.NewRepositoryRegistry(db)
+		service := services.NewServiceRegistry(repository, initStorage())
+		result, err := service.GetField().DeleteOrphanedImages(c.Context(), &dto.DeleteOrphanedImagesRequest{
+			GracePeriod: storageGCGracePeriod,
+			DryRun:      storageGCDryRun,
+			Force:       storageGCForce,
+		})
+		if result != nil {
+			for _, image := range result.Unresolved {
+				logrus.Warnf("image outside the configured storage: %s", image)
+			}
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		for _, key := range result.Orphaned {
+			logrus.Infof("orphaned image: %s", key)
+		}
+		logrus.Infof(
+			"scanned %d objects, %d orphaned, %d deleted (dry run: %t)",
+			result.Scanned,
+			len(result.Orphaned),
+			result.Deleted,
+			storageGCDryRun,
+		)
+	},
+}
+
+func init() {
+	storageGCCommand.Flags().BoolVar(&storageGCDryRun, "dry-run", false, "only report orphaned images")
+	storageGCCommand.Flags().BoolVar(
+		&storageGCForce,
+		"force",
+		false,
+		"delete orphaned images even when some field images are outside the configured storage",
+	)
+	storageGCCommand.Flags().DurationVar(
+		&storageGCGracePeriod,
+		"grace-period",
+		24*time.Hour,
+		"keep objects modified more recently than this",
+	)
+	command.AddCommand(storageGCCommand)
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	clients "field-service/clients/user"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+
+	"github.com/google/uuid"
+)
+
+// WithUser stores the user a request was authenticated as, for the services
+// to authorize against.
+func WithUser(ctx context.Context, user *clients.UserData) context.Context {
+	return context.WithValue(ctx, constants.User, user)
+}
+
+func UserFromContext(ctx context.Context) (*clients.UserData, bool) {
+	user, ok := ctx.Value(constants.User).(*clients.UserData)
+	return user, ok && user != nil
+}
+
+// Authorize checks that the user of ctx holds permission and, for owners, that
+// they own the resource owned by ownerUUID. Pass a nil ownerUUID for
+// resources that have no owner yet. Calls without a user come from other
+// services, which the API key check already authorized.
+func Authorize(ctx context.Context, permission constants.Permission, ownerUUID *uuid.UUID) error {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	if !constants.HasPermission(user.Role, permission) {
+		return errConstant.ErrForbidden
+	}
+
+	if user.Role == constants.Owner && (ownerUUID == nil || *ownerUUID != user.UUID) {
+		return errConstant.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	Closed   = "closed"
+	Open     = "open"
+	HalfOpen = "half-open"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker fails calls fast once failureThreshold calls in a row have
+// failed. After cooldown a single trial call is let through: its success
+// closes the breaker again, its failure keeps it open for another cooldown.
+type CircuitBreaker struct {
+	mutex            sync.Mutex
+	failureThreshold int
+	cooldown         time.Duration
+	state            string
+	failures         int
+	openedAt         time.Time
+}
+
+func New(failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		state:            Closed,
+	}
+}
+
+// Allow returns ErrOpen when the call must not be made. Every allowed call
+// has to be reported with Success, Failure or Release.
+func (b *CircuitBreaker) Allow() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrOpen
+		}
+		b.state = HalfOpen
+		return nil
+	case HalfOpen:
+		return ErrOpen
+	default:
+		return nil
+	}
+}
+
+func (b *CircuitBreaker) Success() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+}
+
+func (b *CircuitBreaker) Failure() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.failureThreshold {
+		b.state = Open
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *CircuitBreaker) State() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
+
+// Release gives up an allowed call without a verdict, e.g. when the caller
+// went away. A pending trial call is handed to the next caller.
+func (b *CircuitBreaker) Release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == HalfOpen {
+		b.state = Open
+	}
+}
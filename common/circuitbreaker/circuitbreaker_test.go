@@ -0,0 +1,60 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	breaker := New(2, time.Minute)
+
+	breaker.Failure()
+	breaker.Success()
+	breaker.Failure()
+	if err := breaker.Allow(); err != nil || breaker.State() != Closed {
+		t.Fatalf("got %v in state %s, want a closed breaker after a reset", err, breaker.State())
+	}
+
+	breaker.Failure()
+	if err := breaker.Allow(); !errors.Is(err, ErrOpen) || breaker.State() != Open {
+		t.Fatalf("got %v in state %s, want an open breaker", err, breaker.State())
+	}
+}
+
+func TestCircuitBreakerTrialCall(t *testing.T) {
+	const cooldown = 10 * time.Millisecond
+
+	tests := []struct {
+		name      string
+		verdict   func(*CircuitBreaker)
+		state     string
+		nextAllow error
+	}{
+		{name: "success closes", verdict: (*CircuitBreaker).Success, state: Closed},
+		{name: "failure reopens", verdict: (*CircuitBreaker).Failure, state: Open, nextAllow: ErrOpen},
+		{name: "release hands the trial on", verdict: (*CircuitBreaker).Release, state: Open},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breaker := New(1, cooldown)
+			breaker.Failure()
+			time.Sleep(2 * cooldown)
+
+			if err := breaker.Allow(); err != nil {
+				t.Fatalf("trial call: got %v, want it allowed after the cooldown", err)
+			}
+			if err := breaker.Allow(); !errors.Is(err, ErrOpen) || breaker.State() != HalfOpen {
+				t.Fatalf("second call: got %v in state %s, want only one trial", err, breaker.State())
+			}
+
+			test.verdict(breaker)
+			if breaker.State() != test.state {
+				t.Fatalf("got state %s, want %s", breaker.State(), test.state)
+			}
+			if err := breaker.Allow(); !errors.Is(err, test.nextAllow) {
+				t.Errorf("next call: got %v, want %v", err, test.nextAllow)
+			}
+		})
+	}
+}
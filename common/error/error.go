@@ -1,65 +1,78 @@
-package error
-
-import (
-	"errors"
-	"fmt"
-	"strings"
-
-	"github.com/go-playground/validator/v10"
-	"github.com/sirupsen/logrus"
-)
-
-type ValidationResponse struct {
-	Field   string `json:"field, omitempty"`
-	Message string `json:"message, omitempty"`
-}
-
-var ErrValidator = map[string]string{}
-
-func ErrValidationResponse(err error) (validationResponse []ValidationResponse) {
-	var fieldErrors validator.ValidationErrors
-	if errors.As(err, &fieldErrors) {
-		for _, err := range fieldErrors {
-			switch err.Tag() {
-			case "required":
-				validationResponse = append(validationResponse, ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is required", err.Field()),
-				})
-			case "email":
-				validationResponse = append(validationResponse, ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is not valid email", err.Field()),
-				})
-			default:
-				errValidator, ok := ErrValidator[err.Tag()]
-				if ok {
-					count := strings.Count(errValidator, "%s")
-					if count == 1 {
-						validationResponse = append(validationResponse, ValidationResponse{
-							Field:   err.Field(),
-							Message: fmt.Sprintf(errValidator, err.Field()),
-						})
-					} else {
-						validationResponse = append(validationResponse, ValidationResponse{
-							Field:   err.Field(),
-							Message: fmt.Sprintf(errValidator, err.Field(), err.Param()),
-						})
-					}
-				} else {
-					validationResponse = append(validationResponse, ValidationResponse{
-						Field:   err.Field(),
-						Message: fmt.Sprintf("something wrong on %s; %s", err.Field(), err.Tag()),
-					})
-				}
-			}
-		}
-	}
-
-	return validationResponse
-}
-
-func WrapError(err error) error {
-	logrus.Errorf("error: %v", err)
-	return err
-}
+package error
+
+import (
+	"context"
+	"errors"
+	"field-service/common/sorting"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+type ValidationResponse struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+var ErrValidator = map[string]string{}
+
+func ErrValidationResponse(err error) (validationResponse []ValidationResponse) {
+	var sortErrors sorting.Errors
+	if errors.As(err, &sortErrors) {
+		for _, err := range sortErrors {
+			validationResponse = append(validationResponse, ValidationResponse{
+				Field:   err.Key,
+				Message: err.Message,
+			})
+		}
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		for _, err := range fieldErrors {
+			switch err.Tag() {
+			case "required":
+				validationResponse = append(validationResponse, ValidationResponse{
+					Field:   err.Field(),
+					Message: fmt.Sprintf("%s is required", err.Field()),
+				})
+			case "email":
+				validationResponse = append(validationResponse, ValidationResponse{
+					Field:   err.Field(),
+					Message: fmt.Sprintf("%s is not valid email", err.Field()),
+				})
+			default:
+				errValidator, ok := ErrValidator[err.Tag()]
+				if ok {
+					count := strings.Count(errValidator, "%s")
+					if count == 1 {
+						validationResponse = append(validationResponse, ValidationResponse{
+							Field:   err.Field(),
+							Message: fmt.Sprintf(errValidator, err.Field()),
+						})
+					} else {
+						validationResponse = append(validationResponse, ValidationResponse{
+							Field:   err.Field(),
+							Message: fmt.Sprintf(errValidator, err.Field(), err.Param()),
+						})
+					}
+				} else {
+					validationResponse = append(validationResponse, ValidationResponse{
+						Field:   err.Field(),
+						Message: fmt.Sprintf("something wrong on %s; %s", err.Field(), err.Tag()),
+					})
+				}
+			}
+		}
+	}
+
+	return validationResponse
+}
+
+// WrapError logs err with the request and trace IDs of ctx and returns it.
+func WrapError(ctx context.Context, err error) error {
+	logrus.WithContext(ctx).Errorf("error: %v", err)
+	return err
+}
@@ -1,116 +1,304 @@
-package gcs
-
-import (
-	"bytes"
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"time"
-
-	"cloud.google.com/go/storage"
-	"github.com/sirupsen/logrus"
-	"google.golang.org/api/option"
-)
-
-type ServiceAccountKeyJSON struct {
-	Type                    string `json:"type"`
-	ProjectID               string `json:"project_id"`
-	PrivateKeyID            string `json:"private_key_id"`
-	PrivateKey              string `json:"private_key"`
-	ClientEmail             string `json:"client_email"`
-	ClientID                string `json:"client_id"`
-	AuthURI                 string `json:"auth_uri"`
-	TokenURI                string `json:"token_uri"`
-	AuthProviderX509CertURL string `json:"auth_provider_x509_cert_url"`
-	ClientX509CertURL       string `json:"client_x509_cert_url"`
-	UniverseDomain          string `json:"universe_domain"`
-}
-
-type GCSClient struct {
-	ServiceAccountKeyJSON ServiceAccountKeyJSON
-	BucketName            string
-}
-
-type IGCSClient interface {
-	UploadFile(context.Context, string, []byte) (string, error)
-}
-
-func NewGCSClient(serviceAccountKeyJSON ServiceAccountKeyJSON, bucketName string) IGCSClient {
-	return &GCSClient{
-		ServiceAccountKeyJSON: serviceAccountKeyJSON,
-		BucketName:            bucketName,
-	}
-}
-
-func (g *GCSClient) createClient(ctx context.Context) (*storage.Client, error) {
-	reqBodyBytes := new(bytes.Buffer)
-	err := json.NewEncoder(reqBodyBytes).Encode(g.ServiceAccountKeyJSON)
-	if err != nil {
-		logrus.Errorf("Failed to encode service account key json: %v", err)
-		return nil, err
-	}
-
-	jsonByte := reqBodyBytes.Bytes()
-	client, err := storage.NewClient(ctx, option.WithCredentialsJSON(jsonByte))
-	if err != nil {
-		logrus.Errorf("Failed to create client: %v", err)
-		return nil, err
-	}
-
-	return client, nil
-}
-
-func (g *GCSClient) UploadFile(ctx context.Context, fileName string, data []byte) (string, error) {
-	var (
-		contentType      = "application/octet-stream"
-		timeoutInSeconds = 60
-	)
-
-	client, err := g.createClient(ctx)
-	if err != nil {
-		logrus.Errorf("Failed to create client: %v", err)
-		return "", err
-	}
-
-	defer func(client *storage.Client) {
-		err := client.Close()
-		if err != nil {
-			logrus.Errorf("Failed to close client: %v", err)
-			return
-		}
-	}(client)
-
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
-	defer cancel()
-
-	bucket := client.Bucket(g.BucketName)
-	object := bucket.Object(fileName)
-	buffer := bytes.NewBuffer(data)
-
-	writer := object.NewWriter(ctx)
-	writer.ChunkSize = 0
-
-	_, err = io.Copy(writer, buffer)
-	if err != nil {
-		logrus.Errorf("failed to copy: %v", err)
-		return "", err
-	}
-
-	err = writer.Close()
-	if err != nil {
-		logrus.Errorf("failed to close writer: %v", err)
-		return "", err
-	}
-
-	_, err = object.Update(ctx, storage.ObjectAttrsToUpdate{
-		ContentType: contentType,
-	})
-	if err != nil {
-		logrus.Errorf("failed to update object: %v", err)
-		return "", err
-	}
-
-	url := fmt.Sprintf("https://storage.googleapis.com/%s/%s", g.BucketName, fileName)
-	return url, nil
-}
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	fileStorage "field-service/common/storage"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+	gcsIterator "google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+type ServiceAccountKeyJSON struct {
+	Type                    string `json:"type"`
+	ProjectID               string `json:"project_id"`
+	PrivateKeyID            string `json:"private_key_id"`
+	PrivateKey              string `json:"private_key"`
+	ClientEmail             string `json:"client_email"`
+	ClientID                string `json:"client_id"`
+	AuthURI                 string `json:"auth_uri"`
+	TokenURI                string `json:"token_uri"`
+	AuthProviderX509CertURL string `json:"auth_provider_x509_cert_url"`
+	ClientX509CertURL       string `json:"client_x509_cert_url"`
+	UniverseDomain          string `json:"universe_domain"`
+}
+
+type GCSClient struct {
+	ServiceAccountKeyJSON ServiceAccountKeyJSON
+	BucketName            string
+}
+
+func NewGCSClient(serviceAccountKeyJSON ServiceAccountKeyJSON, bucketName string) fileStorage.IStorage {
+	return &GCSClient{
+		ServiceAccountKeyJSON: serviceAccountKeyJSON,
+		BucketName:            bucketName,
+	}
+}
+
+func (g *GCSClient) createClient(ctx context.Context) (*storage.Client, error) {
+	reqBodyBytes := new(bytes.Buffer)
+	err := json.NewEncoder(reqBodyBytes).Encode(g.ServiceAccountKeyJSON)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to encode service account key json: %v", err)
+		return nil, err
+	}
+
+	jsonByte := reqBodyBytes.Bytes()
+	client, err := storage.NewClient(ctx, option.WithCredentialsJSON(jsonByte))
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to create client: %v", err)
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (g *GCSClient) UploadFile(ctx context.Context, fileName, contentType string, data []byte) (string, error) {
+	timeoutInSeconds := 60
+
+	client, err := g.createClient(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to create client: %v", err)
+		return "", err
+	}
+
+	defer func(client *storage.Client) {
+		err := client.Close()
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("Failed to close client: %v", err)
+			return
+		}
+	}(client)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	bucket := client.Bucket(g.BucketName)
+	object := bucket.Object(fileName)
+	buffer := bytes.NewBuffer(data)
+
+	writer := object.NewWriter(ctx)
+	writer.ChunkSize = 0
+	writer.ContentType = contentType
+
+	_, err = io.Copy(writer, buffer)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to copy: %v", err)
+		return "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to close writer: %v", err)
+		return "", err
+	}
+
+	url := g.ObjectURL(fileName)
+	return url, nil
+}
+
+func (g *GCSClient) ObjectURL(fileName string) string {
+	return fmt.Sprintf("%s%s", g.baseURL(), fileName)
+}
+
+func (g *GCSClient) baseURL() string {
+	return fmt.Sprintf("https://storage.googleapis.com/%s/", g.BucketName)
+}
+
+func (g *GCSClient) ObjectKey(fileURL string) (string, bool) {
+	return strings.CutPrefix(fileURL, g.baseURL())
+}
+
+func (g *GCSClient) DeleteFile(ctx context.Context, fileName string) error {
+	timeoutInSeconds := 60
+
+	client, err := g.createClient(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to create client: %v", err)
+		return err
+	}
+
+	defer func(client *storage.Client) {
+		err := client.Close()
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("Failed to close client: %v", err)
+			return
+		}
+	}(client)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	err = client.Bucket(g.BucketName).Object(fileName).Delete(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to delete object: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (g *GCSClient) ReadFile(ctx context.Context, fileName string) ([]byte, error) {
+	timeoutInSeconds := 60
+
+	client, err := g.createClient(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to create client: %v", err)
+		return nil, err
+	}
+
+	defer func(client *storage.Client) {
+		err := client.Close()
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("Failed to close client: %v", err)
+			return
+		}
+	}(client)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	reader, err := client.Bucket(g.BucketName).Object(fileName).NewReader(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to open object: %v", err)
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to read object: %v", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (g *GCSClient) SignedURL(ctx context.Context, fileName string, expiry time.Duration) (string, error) {
+	url, err := storage.SignedURL(g.BucketName, fileName, &storage.SignedURLOptions{
+		GoogleAccessID: g.ServiceAccountKeyJSON.ClientEmail,
+		PrivateKey:     []byte(g.ServiceAccountKeyJSON.PrivateKey),
+		Method:         http.MethodGet,
+		Expires:        time.Now().Add(expiry),
+		Scheme:         storage.SigningSchemeV4,
+	})
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to sign url: %v", err)
+		return "", err
+	}
+	return url, nil
+}
+
+func (g *GCSClient) Exists(ctx context.Context, fileName string) (bool, error) {
+	timeoutInSeconds := 60
+
+	client, err := g.createClient(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to create client: %v", err)
+		return false, err
+	}
+
+	defer func(client *storage.Client) {
+		err := client.Close()
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("Failed to close client: %v", err)
+			return
+		}
+	}(client)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	_, err = client.Bucket(g.BucketName).Object(fileName).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return false, nil
+		}
+
+		logrus.WithContext(ctx).Errorf("failed to get object attrs: %v", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// PresignUpload signs a PUT of contentType; the x-goog-content-length-range
+// header makes GCS reject bodies larger than size.
+func (g *GCSClient) PresignUpload(
+	ctx context.Context,
+	fileName, contentType string,
+	size int64,
+	expiry time.Duration,
+) (*fileStorage.PresignedUpload, error) {
+	contentLengthRange := fmt.Sprintf("0,%d", size)
+	expiresAt := time.Now().Add(expiry)
+	url, err := storage.SignedURL(g.BucketName, fileName, &storage.SignedURLOptions{
+		GoogleAccessID: g.ServiceAccountKeyJSON.ClientEmail,
+		PrivateKey:     []byte(g.ServiceAccountKeyJSON.PrivateKey),
+		Method:         http.MethodPut,
+		ContentType:    contentType,
+		Headers:        []string{fmt.Sprintf("x-goog-content-length-range:%s", contentLengthRange)},
+		Expires:        expiresAt,
+		Scheme:         storage.SigningSchemeV4,
+	})
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to sign upload url: %v", err)
+		return nil, err
+	}
+
+	return &fileStorage.PresignedUpload{
+		URL:    url,
+		Method: http.MethodPut,
+		Headers: map[string]string{
+			"Content-Type":                contentType,
+			"X-Goog-Content-Length-Range": contentLengthRange,
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// List returns every object whose key starts with prefix.
+func (g *GCSClient) List(ctx context.Context, prefix string) ([]fileStorage.Object, error) {
+	client, err := g.createClient(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to create client: %v", err)
+		return nil, err
+	}
+
+	defer func(client *storage.Client) {
+		err := client.Close()
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("Failed to close client: %v", err)
+			return
+		}
+	}(client)
+
+	objects := make([]fileStorage.Object, 0)
+	iterator := client.Bucket(g.BucketName).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := iterator.Next()
+		if errors.Is(err, gcsIterator.Done) {
+			break
+		}
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("failed to list objects: %v", err)
+			return nil, err
+		}
+
+		objects = append(objects, fileStorage.Object{
+			Key:       attrs.Name,
+			UpdatedAt: attrs.Updated,
+		})
+	}
+
+	return objects, nil
+}
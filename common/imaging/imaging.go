@@ -0,0 +1,286 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	errConstant "field-service/constants/error"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/gabriel-vasile/mimetype"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	Thumbnail = "thumbnail"
+	Medium    = "medium"
+	Original  = "original"
+
+	// Renditions are always stored as JPEG. WebP uploads are accepted but
+	// not produced: x/image only decodes WebP, and encoding it would need
+	// cgo and libwebp in the runtime image, so WebP output is out of scope.
+	ContentType = "image/jpeg"
+	Extension   = ".jpg"
+
+	jpegQuality = 85
+
+	// MaxDimension bounds the width and height of uploads and MaxPixels
+	// their area. Both are checked before decoding, so a small file cannot
+	// expand into a huge bitmap. A 16 MP image takes 64 MB per RGBA copy and
+	// processing holds a few of them, so storage.uploadConcurrency images
+	// at a time stay within a few hundred MB.
+	MaxDimension = 8000
+	MaxPixels    = 16_000_000
+)
+
+// Types maps the content types accepted for uploads to their file extension.
+var Types = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Rendition is one stored size of an uploaded image. Images are scaled down
+// to fit a MaxSize x MaxSize box; a MaxSize of 0 keeps the source size.
+type Rendition struct {
+	Name    string
+	MaxSize int
+}
+
+var Renditions = []Rendition{
+	{Name: Thumbnail, MaxSize: 320},
+	{Name: Medium, MaxSize: 1024},
+	{Name: Original, MaxSize: 0},
+}
+
+type Output struct {
+	Name string
+	Data []byte
+}
+
+// Sniff detects the content type of data from its magic bytes, ignoring
+// whatever the client claimed, and rejects types missing from Types.
+func Sniff(data []byte) (string, error) {
+	contentType := mimetype.Detect(data).String()
+	if _, ok := Types[contentType]; !ok {
+		return "", errConstant.ErrUnsupportedImage
+	}
+	return contentType, nil
+}
+
+// Process decodes an uploaded image, applies its EXIF orientation and
+// re-encodes every rendition as JPEG. Re-encoding drops all metadata, EXIF
+// included, from the stored files.
+func Process(data []byte) ([]Output, error) {
+	_, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errConstant.ErrInValidUploadFile
+	}
+
+	if config.Width > MaxDimension || config.Height > MaxDimension ||
+		int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, errConstant.ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errConstant.ErrInValidUploadFile
+	}
+
+	if format == "jpeg" {
+		img = orient(img, orientation(data))
+	}
+	canvas := flatten(img)
+
+	outputs := make([]Output, 0, len(Renditions))
+	for _, rendition := range Renditions {
+		buffer := new(bytes.Buffer)
+		err = jpeg.Encode(buffer, fit(canvas, rendition.MaxSize), &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return nil, err
+		}
+
+		outputs = append(outputs, Output{
+			Name: rendition.Name,
+			Data: buffer.Bytes(),
+		})
+	}
+
+	return outputs, nil
+}
+
+// RenditionURLs expands the URL or object key of an original rendition into
+// those of all renditions stored next to it. Images uploaded before renditions
+// existed only have an original.
+func RenditionURLs(originalURL string) map[string]string {
+	urls := map[string]string{Original: originalURL}
+
+	base, ok := strings.CutSuffix(originalURL, Original+Extension)
+	if !ok {
+		return urls
+	}
+
+	for _, rendition := range Renditions {
+		urls[rendition.Name] = base + rendition.Name + Extension
+	}
+	return urls
+}
+
+// flatten draws img onto an opaque white canvas, as JPEG has no alpha channel.
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(canvas, canvas.Bounds(), img, bounds.Min, draw.Over)
+	return canvas
+}
+
+func fit(img *image.RGBA, maxSize int) image.Image {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if maxSize <= 0 || (width <= maxSize && height <= maxSize) {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*maxSize/width)
+		width = maxSize
+	} else {
+		width = max(1, width*maxSize/height)
+		height = maxSize
+	}
+
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return resized
+}
+
+// orientation reads the EXIF orientation tag of a JPEG, returning 1 (upright)
+// when there is none.
+func orientation(data []byte) int {
+	const (
+		markerSOS         = 0xDA
+		markerAPP1        = 0xE1
+		tagOrientation    = 0x0112
+		ifdEntryLength    = 12
+		exifHeaderLength  = 6
+		tiffHeaderLength  = 8
+		segmentHeaderSize = 4
+	)
+
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for offset := 2; offset+segmentHeaderSize <= len(data); {
+		if data[offset] != 0xFF {
+			return 1
+		}
+
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if marker == markerSOS || length < 2 || offset+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[offset+segmentHeaderSize : offset+2+length]
+		offset += 2 + length
+		if marker != markerAPP1 || !bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			continue
+		}
+
+		tiff := segment[exifHeaderLength:]
+		if len(tiff) < tiffHeaderLength {
+			return 1
+		}
+
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return 1
+		}
+
+		ifd := int(order.Uint32(tiff[4:]))
+		if ifd+2 > len(tiff) {
+			return 1
+		}
+
+		entries := int(order.Uint16(tiff[ifd:]))
+		for i := 0; i < entries; i++ {
+			entry := ifd + 2 + i*ifdEntryLength
+			if entry+ifdEntryLength > len(tiff) {
+				return 1
+			}
+
+			if order.Uint16(tiff[entry:]) == tagOrientation {
+				value := int(order.Uint16(tiff[entry+8:]))
+				if value < 1 || value > 8 {
+					return 1
+				}
+				return value
+			}
+		}
+		return 1
+	}
+
+	return 1
+}
+
+// orient turns img upright according to an EXIF orientation value.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = width-1-x, y
+			case 3:
+				sx, sy = width-1-x, height-1-y
+			case 4:
+				sx, sy = x, height-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, height-1-x
+			case 7:
+				sx, sy = width-1-y, height-1-x
+			case 8:
+				sx, sy = width-1-y, x
+			}
+
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,339 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	errConstant "field-service/constants/error"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{R: 0xFF, A: 0xFF}
+	white = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+)
+
+// testImage is white with a red block in its top-left corner, so the corner
+// it ends up in shows how an image was turned.
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, white)
+			if x < width/4 && y < height/4 {
+				img.Set(x, y, red)
+			}
+		}
+	}
+	return img
+}
+
+func encode(t *testing.T, format string, img image.Image) []byte {
+	t.Helper()
+	buffer := new(bytes.Buffer)
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(buffer, img)
+	case "gif":
+		err = gif.Encode(buffer, img, nil)
+	case "jpeg":
+		err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: 95})
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// withOrientation inserts an EXIF segment holding orientation right after the
+// start of a JPEG.
+func withOrientation(data []byte, orientation int, order binary.ByteOrder) []byte {
+	tiff := new(bytes.Buffer)
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	_ = binary.Write(tiff, order, uint16(42))
+	_ = binary.Write(tiff, order, uint32(8))
+	_ = binary.Write(tiff, order, uint16(1))
+	_ = binary.Write(tiff, order, uint16(0x0112))
+	_ = binary.Write(tiff, order, uint16(3))
+	_ = binary.Write(tiff, order, uint32(1))
+	_ = binary.Write(tiff, order, uint16(orientation))
+	_ = binary.Write(tiff, order, uint16(0))
+	_ = binary.Write(tiff, order, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	result := append([]byte{}, data[:2]...)
+	result = append(result, segment...)
+	return append(result, data[2:]...)
+}
+
+func decodeOutput(t *testing.T, outputs []Output, name string) image.Image {
+	t.Helper()
+	for _, output := range outputs {
+		if output.Name != name {
+			continue
+		}
+
+		img, format, err := image.Decode(bytes.NewReader(output.Data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format != "jpeg" {
+			t.Fatalf("got rendition %s as %s, want jpeg", name, format)
+		}
+		return img
+	}
+	t.Fatalf("no rendition %s", name)
+	return nil
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		width  int
+		height int
+		want   map[string]image.Point
+	}{
+		{
+			name:   "landscape png",
+			format: "png",
+			width:  2000,
+			height: 1000,
+			want:   map[string]image.Point{Thumbnail: {320, 160}, Medium: {1024, 512}, Original: {2000, 1000}},
+		},
+		{
+			name:   "portrait jpeg",
+			format: "jpeg",
+			width:  600,
+			height: 1200,
+			want:   map[string]image.Point{Thumbnail: {160, 320}, Medium: {512, 1024}, Original: {600, 1200}},
+		},
+		{
+			name:   "small gif",
+			format: "gif",
+			width:  100,
+			height: 50,
+			want:   map[string]image.Point{Thumbnail: {100, 50}, Medium: {100, 50}, Original: {100, 50}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputs, err := Process(encode(t, test.format, testImage(test.width, test.height)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(outputs) != len(Renditions) {
+				t.Fatalf("got %d renditions, want %d", len(outputs), len(Renditions))
+			}
+
+			for name, want := range test.want {
+				img := decodeOutput(t, outputs, name)
+				if got := img.Bounds().Size(); got != want {
+					t.Errorf("got %s of %v, want %v", name, got, want)
+				}
+				if !isRed(img.At(1, 1)) {
+					t.Errorf("got %s without the red top-left corner", name)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessFlattensTransparency(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	outputs, err := Process(encode(t, "png", img))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, g, b, _ := decodeOutput(t, outputs, Original).At(4, 4).RGBA()
+	if r < 0xF000 || g < 0xF000 || b < 0xF000 {
+		t.Errorf("got transparent pixels as (%x, %x, %x), want white", r, g, b)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	data := encode(t, "png", testImage(16, 16))
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "text", data: []byte("not an image at all"), want: errConstant.ErrUnsupportedImage},
+		{name: "pdf", data: []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"), want: errConstant.ErrUnsupportedImage},
+		{name: "truncated png", data: data[:len(data)/2], want: errConstant.ErrInValidUploadFile},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Process(test.data)
+			if !errors.Is(err, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	data := encode(t, "jpeg", testImage(40, 20))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "no exif", data: data, want: 1},
+		{name: "not a jpeg", data: encode(t, "png", testImage(4, 4)), want: 1},
+		{name: "little endian", data: withOrientation(data, 6, binary.LittleEndian), want: 6},
+		{name: "big endian", data: withOrientation(data, 8, binary.BigEndian), want: 8},
+		{name: "out of range", data: withOrientation(data, 9, binary.BigEndian), want: 1},
+		{name: "truncated", data: withOrientation(data, 3, binary.BigEndian)[:20], want: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := orientation(test.data); got != test.want {
+				t.Errorf("got orientation %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestProcessOrients(t *testing.T) {
+	data := encode(t, "jpeg", testImage(40, 20))
+
+	tests := []struct {
+		orientation int
+		size        image.Point
+		redCorner   string
+	}{
+		{orientation: 1, size: image.Pt(40, 20), redCorner: "top-left"},
+		{orientation: 2, size: image.Pt(40, 20), redCorner: "top-right"},
+		{orientation: 3, size: image.Pt(40, 20), redCorner: "bottom-right"},
+		{orientation: 4, size: image.Pt(40, 20), redCorner: "bottom-left"},
+		{orientation: 5, size: image.Pt(20, 40), redCorner: "top-left"},
+		{orientation: 6, size: image.Pt(20, 40), redCorner: "top-right"},
+		{orientation: 7, size: image.Pt(20, 40), redCorner: "bottom-right"},
+		{orientation: 8, size: image.Pt(20, 40), redCorner: "bottom-left"},
+	}
+	for _, test := range tests {
+		t.Run(test.redCorner, func(t *testing.T) {
+			outputs, err := Process(withOrientation(data, test.orientation, binary.BigEndian))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			img := decodeOutput(t, outputs, Original)
+			if got := img.Bounds().Size(); got != test.size {
+				t.Fatalf("orientation %d: got %v, want %v", test.orientation, got, test.size)
+			}
+
+			width, height := test.size.X, test.size.Y
+			corners := map[string]image.Point{
+				"top-left":     {1, 1},
+				"top-right":    {width - 2, 1},
+				"bottom-left":  {1, height - 2},
+				"bottom-right": {width - 2, height - 2},
+			}
+			for corner, point := range corners {
+				if got := isRed(img.At(point.X, point.Y)); got != (corner == test.redCorner) {
+					t.Errorf("orientation %d: got red %t in the %s corner", test.orientation, got, corner)
+				}
+			}
+		})
+	}
+}
+
+func TestRenditionURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		want     map[string]string
+	}{
+		{
+			name:     "renditions",
+			original: "https://cdn.example.com/images/a/original.jpg",
+			want: map[string]string{
+				Thumbnail: "https://cdn.example.com/images/a/thumbnail.jpg",
+				Medium:    "https://cdn.example.com/images/a/medium.jpg",
+				Original:  "https://cdn.example.com/images/a/original.jpg",
+			},
+		},
+		{
+			name:     "legacy image",
+			original: "https://cdn.example.com/images/photo.png",
+			want:     map[string]string{Original: "https://cdn.example.com/images/photo.png"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := RenditionURLs(test.original)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+			for name, url := range test.want {
+				if got[name] != url {
+					t.Errorf("got %s %q, want %q", name, got[name], url)
+				}
+			}
+		})
+	}
+}
+
+// pngHeader is a PNG that ends after its IHDR chunk, enough for
+// image.DecodeConfig but not for decoding.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12] = 8 // bit depth
+	ihdr[13] = 6 // RGBA
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, 13)
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestProcessLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  uint32
+		height uint32
+		want   error
+	}{
+		// Passes the limits, so it fails only when decoded.
+		{name: "at the limits", width: 4000, height: 4000, want: errConstant.ErrInValidUploadFile},
+		{name: "too wide", width: MaxDimension + 1, height: 10, want: errConstant.ErrImageTooLarge},
+		{name: "too tall", width: 10, height: MaxDimension + 1, want: errConstant.ErrImageTooLarge},
+		{name: "too many pixels", width: 5000, height: 4000, want: errConstant.ErrImageTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Process(pngHeader(test.width, test.height))
+			if !errors.Is(err, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
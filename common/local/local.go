@@ -0,0 +1,257 @@
+package local
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"field-service/common/storage"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// LocalClient stores objects on the local disk under Path. It needs no cloud
+// credentials and is meant for development and tests; the files are served
+// from BaseURL by a static route.
+type LocalClient struct {
+	Path         string
+	BaseURL      string
+	SignatureKey string
+}
+
+func NewLocalClient(path, baseURL, signatureKey string) storage.IStorage {
+	return &LocalClient{
+		Path:         path,
+		BaseURL:      strings.TrimSuffix(baseURL, "/"),
+		SignatureKey: signatureKey,
+	}
+}
+
+// filePath resolves a key inside Path. Cleaning the key as an absolute path
+// first keeps "../" segments from escaping the storage directory.
+func (l *LocalClient) filePath(fileName string) string {
+	return filepath.Join(l.Path, filepath.FromSlash(path.Clean("/"+fileName)))
+}
+
+// UploadFile ignores contentType; the static route derives it from the file
+// extension when serving.
+func (l *LocalClient) UploadFile(ctx context.Context, fileName, _ string, data []byte) (string, error) {
+	filePath := l.filePath(fileName)
+	err := os.MkdirAll(filepath.Dir(filePath), 0o755)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to create directory: %v", err)
+		return "", err
+	}
+
+	err = os.WriteFile(filePath, data, 0o644)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to write file: %v", err)
+		return "", err
+	}
+
+	return l.ObjectURL(fileName), nil
+}
+
+func (l *LocalClient) ObjectURL(fileName string) string {
+	return fmt.Sprintf("%s/%s", l.BaseURL, fileName)
+}
+
+func (l *LocalClient) DeleteFile(ctx context.Context, fileName string) error {
+	err := os.Remove(l.filePath(fileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logrus.WithContext(ctx).Errorf("failed to delete file: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (l *LocalClient) ReadFile(ctx context.Context, fileName string) ([]byte, error) {
+	data, err := os.ReadFile(l.filePath(fileName))
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to read file: %v", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+func (l *LocalClient) Exists(_ context.Context, fileName string) (bool, error) {
+	_, err := os.Stat(l.filePath(fileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (l *LocalClient) ObjectKey(fileURL string) (string, bool) {
+	return strings.CutPrefix(fileURL, l.BaseURL+"/")
+}
+
+func (l *LocalClient) signature(fileName string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(l.SignatureKey))
+	mac.Write([]byte(fmt.Sprintf("%s:%d", fileName, expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *LocalClient) SignedURL(_ context.Context, fileName string, expiry time.Duration) (string, error) {
+	expires := time.Now().Add(expiry).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", l.signature(fileName, expires))
+	return fmt.Sprintf("%s/%s?%s", l.BaseURL, fileName, query.Encode()), nil
+}
+
+// VerifySignature guards the static route when images are handed out as
+// signed URLs, rejecting requests without a valid, unexpired signature.
+func (l *LocalClient) VerifySignature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fileName := strings.TrimPrefix(c.Param("filepath"), "/")
+		expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+		if err != nil || time.Now().Unix() > expires {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		signature := l.signature(fileName, expires)
+		if !hmac.Equal([]byte(signature), []byte(c.Query("signature"))) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// uploadSignature covers everything the upload handler enforces, so a signed
+// PUT cannot be replayed against another key, content type or size limit.
+func (l *LocalClient) uploadSignature(fileName, contentType string, size, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(l.SignatureKey))
+	mac.Write([]byte(fmt.Sprintf("%s:%s:%s:%d:%d", http.MethodPut, fileName, contentType, size, expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *LocalClient) PresignUpload(
+	_ context.Context,
+	fileName, contentType string,
+	size int64,
+	expiry time.Duration,
+) (*storage.PresignedUpload, error) {
+	expiresAt := time.Now().Add(expiry)
+	expires := expiresAt.Unix()
+	query := url.Values{}
+	query.Set("contentType", contentType)
+	query.Set("size", strconv.FormatInt(size, 10))
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", l.uploadSignature(fileName, contentType, size, expires))
+
+	return &storage.PresignedUpload{
+		URL:       fmt.Sprintf("%s?%s", l.ObjectURL(fileName), query.Encode()),
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// UploadHandler accepts the PUT requests signed by PresignUpload. It is
+// mounted on the same route the files are served from.
+func (l *LocalClient) UploadHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fileName := strings.TrimPrefix(c.Param("filepath"), "/")
+		contentType := c.Query("contentType")
+		size, err := strconv.ParseInt(c.Query("size"), 10, 64)
+		if err != nil {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+		if err != nil || time.Now().Unix() > expires {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		signature := l.uploadSignature(fileName, contentType, size, expires)
+		if !hmac.Equal([]byte(signature), []byte(c.Query("signature"))) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if c.GetHeader("Content-Type") != contentType {
+			c.AbortWithStatus(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		data, err := io.ReadAll(io.LimitReader(c.Request.Body, size+1))
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if int64(len(data)) > size {
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		_, err = l.UploadFile(c.Request.Context(), fileName, contentType, data)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
+
+// List returns every file whose key starts with prefix.
+func (l *LocalClient) List(ctx context.Context, prefix string) ([]storage.Object, error) {
+	objects := make([]storage.Object, 0)
+	err := filepath.WalkDir(l.Path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(l.Path, filePath)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, storage.Object{
+			Key:       key,
+			UpdatedAt: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("failed to list files: %v", err)
+		return nil, err
+	}
+
+	return objects, nil
+}
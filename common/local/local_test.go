@@ -0,0 +1,132 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestClient(t *testing.T) *LocalClient {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	return NewLocalClient(t.TempDir(), "http://localhost/storage/", "secret").(*LocalClient)
+}
+
+// storagePath turns a URL handed out by the client into the path the router
+// serves it on.
+func storagePath(t *testing.T, rawURL string) string {
+	t.Helper()
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.RequestURI()
+}
+
+func TestVerifySignature(t *testing.T) {
+	client := newTestClient(t)
+	router := gin.New()
+	router.GET("/storage/*filepath", client.VerifySignature(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	signed, err := client.SignedURL(context.Background(), "field/image.webp", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := client.SignedURL(context.Background(), "field/image.webp", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := &LocalClient{BaseURL: client.BaseURL, SignatureKey: "other"}
+	forged, err := other.SignedURL(context.Background(), "field/image.webp", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedPath := storagePath(t, signed)
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{name: "signed", path: signedPath, want: http.StatusOK},
+		{name: "other file", path: strings.Replace(signedPath, "image.webp", "other.webp", 1), want: http.StatusForbidden},
+		{name: "later expiry", path: strings.Replace(signedPath, "expires=", "expires=9", 1), want: http.StatusForbidden},
+		{name: "expired", path: storagePath(t, expired), want: http.StatusForbidden},
+		{name: "other key", path: storagePath(t, forged), want: http.StatusForbidden},
+		{name: "unsigned", path: "/storage/field/image.webp", want: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if recorder.Code != test.want {
+				t.Errorf("got status %d, want %d", recorder.Code, test.want)
+			}
+		})
+	}
+}
+
+func TestUploadHandler(t *testing.T) {
+	client := newTestClient(t)
+	router := gin.New()
+	router.PUT("/storage/*filepath", client.UploadHandler())
+
+	upload, err := client.PresignUpload(context.Background(), "raw/image.png", "image/png", 4, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := client.PresignUpload(context.Background(), "raw/image.png", "image/png", 4, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploadPath := storagePath(t, upload.URL)
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "signed", path: uploadPath, contentType: "image/png", body: "data", want: http.StatusOK},
+		{name: "shorter body", path: uploadPath, contentType: "image/png", body: "da", want: http.StatusOK},
+		{name: "other file", path: strings.Replace(uploadPath, "image.png", "other.png", 1),
+			contentType: "image/png", body: "data", want: http.StatusForbidden},
+		{name: "larger size", path: strings.Replace(uploadPath, "size=4", "size=40", 1),
+			contentType: "image/png", body: "data", want: http.StatusForbidden},
+		{name: "other signed type", path: strings.Replace(uploadPath, "image%2Fpng", "image%2Fgif", 1),
+			contentType: "image/gif", body: "data", want: http.StatusForbidden},
+		{name: "expired", path: storagePath(t, expired.URL), contentType: "image/png", body: "data", want: http.StatusForbidden},
+		{name: "wrong content type", path: uploadPath, contentType: "image/gif", body: "data", want: http.StatusUnsupportedMediaType},
+		{name: "too large", path: uploadPath, contentType: "image/png", body: "data!", want: http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if recorder.Code != test.want {
+				t.Errorf("got status %d, want %d", recorder.Code, test.want)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(filepath.Join(client.Path, "raw", "image.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "da" {
+		t.Errorf("got %q stored, want the last accepted upload", data)
+	}
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"field-service/common/trace"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are matched case-insensitively against log field and header
+// names; a name containing any of them never has its value logged.
+var sensitiveKeys = []string{
+	"authorization",
+	"api-key",
+	"api_key",
+	"apikey",
+	"token",
+	"password",
+	"secret",
+	"signature",
+	"cookie",
+}
+
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitiveKey := range sensitiveKeys {
+		if strings.Contains(key, sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders flattens header for logging, hiding sensitive values.
+func RedactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for key, values := range header {
+		if IsSensitive(key) {
+			headers[key] = redacted
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// RedactHook hides the values of sensitive fields in every log entry.
+type RedactHook struct{}
+
+func (RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (RedactHook) Fire(entry *logrus.Entry) error {
+	for key := range entry.Data {
+		if IsSensitive(key) {
+			entry.Data[key] = redacted
+		}
+	}
+	return nil
+}
+
+// Init configures the standard logrus logger. Format is "json" (the default)
+// or "text"; level defaults to info.
+func Init(level, format string) {
+	if format == "text" {
+		logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	} else {
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		logLevel = logrus.InfoLevel
+	}
+	logrus.SetLevel(logLevel)
+	logrus.AddHook(trace.Hook{})
+	logrus.AddHook(RedactHook{})
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "field_service"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route pattern and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database statement latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	UserClientRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "user_client_request_duration_seconds",
+		Help:      "Latency of single calls to the user service by result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	UserClientErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "user_client_errors_total",
+		Help:      "Failed user lookups by reason.",
+	}, []string{"reason"})
+
+	StorageUploadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_upload_duration_seconds",
+		Help:      "Object upload latency by storage driver and result.",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"driver", "result"})
+
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by rate limit rule.",
+	}, []string{"rule"})
+
+	BookingsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_created_total",
+		Help:      "Field schedules that changed from available to booked.",
+	})
+)
+
+// Result labels an outcome for the duration histograms.
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// RegisterUserCache exposes the hit and miss counts of the user cache.
+func RegisterUserCache(hits, misses func() float64) {
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "user_client_cache_hits_total",
+		Help:      "User lookups answered from the cache.",
+	}, hits)
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "user_client_cache_misses_total",
+		Help:      "User lookups the cache passed on to the user service.",
+	}, misses)
+}
+
+// RegisterCircuitBreaker exposes the state of the user client's breaker as
+// 0 (closed), 1 (half-open) or 2 (open).
+func RegisterCircuitBreaker(state func() float64) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "user_client_circuit_breaker_state",
+		Help:      "State of the user client circuit breaker: 0 closed, 1 half-open, 2 open.",
+	}, state)
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"field-service/domain/dto"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// slotsTimeout bounds the query that refreshes the slot gauge.
+const slotsTimeout = 5 * time.Second
+
+// slotCollector caches the available slots for refresh, so scrapes do not
+// query the database each time. A failed refresh keeps the last counts and is
+// retried after another refresh interval.
+type slotCollector struct {
+	desc      *prometheus.Desc
+	count     func(context.Context) ([]dto.FieldAvailableSlotCount, error)
+	refresh   time.Duration
+	mutex     sync.Mutex
+	counts    []dto.FieldAvailableSlotCount
+	fetchedAt time.Time
+}
+
+func (c *slotCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *slotCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, count := range c.current() {
+		ch <- prometheus.MustNewConstMetric(
+			c.desc,
+			prometheus.GaugeValue,
+			float64(count.Available),
+			count.FieldUUID,
+			count.FieldName,
+		)
+	}
+}
+
+// current returns the cached counts, refreshing them first once they are
+// older than refresh. Concurrent scrapes wait for the same refresh.
+func (c *slotCollector) current() []dto.FieldAvailableSlotCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.refresh {
+		return c.counts
+	}
+	c.fetchedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), slotsTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		logrus.Errorf("failed to count available slots: %v", err)
+		return c.counts
+	}
+	c.counts = counts
+	return c.counts
+}
+
+// RegisterAvailableSlots exposes the available slots per field for the next
+// days as counted by count, at most once per refresh.
+func RegisterAvailableSlots(
+	days int,
+	refresh time.Duration,
+	count func(context.Context, int) ([]dto.FieldAvailableSlotCount, error),
+) {
+	prometheus.MustRegister(&slotCollector{
+		refresh: refresh,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "field_slots_available"),
+			"Available schedules per field for the next days, starting today.",
+			[]string{"field_uuid", "field_name"},
+			prometheus.Labels{"days": strconv.Itoa(days)},
+		),
+		count: func(ctx context.Context) ([]dto.FieldAvailableSlotCount, error) {
+			return count(ctx, days)
+		},
+	})
+}
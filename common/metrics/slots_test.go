@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"field-service/domain/dto"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSlotCollectorCaches(t *testing.T) {
+	queries := 0
+	var err error
+	collector := &slotCollector{
+		desc: prometheus.NewDesc("slots", "test", []string{"field_uuid", "field_name"}, nil),
+		count: func(context.Context) ([]dto.FieldAvailableSlotCount, error) {
+			queries++
+			if err != nil {
+				return nil, err
+			}
+			return []dto.FieldAvailableSlotCount{{FieldUUID: "a", FieldName: "A", Available: int64(queries)}}, nil
+		},
+		refresh: time.Hour,
+	}
+
+	tests := []struct {
+		name        string
+		stale       bool
+		err         error
+		wantQueries int
+		wantCount   int
+	}{
+		{name: "first scrape", wantQueries: 1, wantCount: 1},
+		{name: "cached", wantQueries: 1, wantCount: 1},
+		{name: "stale", stale: true, wantQueries: 2, wantCount: 1},
+		{name: "failed refresh keeps counts", stale: true, err: errors.New("down"), wantQueries: 3, wantCount: 1},
+		{name: "failed refresh is not retried at once", err: errors.New("down"), wantQueries: 3, wantCount: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err = test.err
+			if test.stale {
+				collector.fetchedAt = time.Now().Add(-2 * collector.refresh)
+			}
+
+			if got := testutil.CollectAndCount(collector); got != test.wantCount {
+				t.Errorf("got %d metrics, want %d", got, test.wantCount)
+			}
+			if queries != test.wantQueries {
+				t.Errorf("got %d queries, want %d", queries, test.wantQueries)
+			}
+		})
+	}
+}
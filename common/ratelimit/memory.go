@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired windows are dropped from memory.
+const sweepInterval = time.Minute
+
+type window struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the counters of this instance only, so every replica
+// enforces the limits on its own.
+type MemoryStore struct {
+	mutex     sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{windows: make(map[string]*window)}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit int, length time.Duration) (*Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > sweepInterval {
+		for k, w := range s.windows {
+			if !now.Before(w.expiresAt) {
+				delete(s.windows, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.expiresAt) {
+		w = &window{expiresAt: now.Add(length)}
+		s.windows[key] = w
+	}
+	w.count++
+
+	return newResult(w.count, limit, w.expiresAt.Sub(now)), nil
+}
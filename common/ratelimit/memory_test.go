@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	tests := []struct {
+		key       string
+		remaining int
+		allowed   bool
+	}{
+		{key: "a", remaining: 1, allowed: true},
+		{key: "a", remaining: 0, allowed: true},
+		{key: "b", remaining: 1, allowed: true},
+		{key: "a", remaining: 0, allowed: false},
+		{key: "b", remaining: 0, allowed: true},
+	}
+	for i, test := range tests {
+		result, err := store.Take(ctx, test.key, 2, time.Minute)
+		if err != nil {
+			t.Fatalf("take %d: %v", i, err)
+		}
+		if result.Allowed != test.allowed || result.Remaining != test.remaining || result.Limit != 2 {
+			t.Errorf("take %d of %q: got %+v, want allowed %t remaining %d",
+				i, test.key, result, test.allowed, test.remaining)
+		}
+		if result.ResetIn <= 0 || result.ResetIn > time.Minute {
+			t.Errorf("take %d: reset in %s, want within the window", i, result.ResetIn)
+		}
+	}
+}
+
+func TestMemoryStoreTakeResetsAfterWindow(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := store.Take(ctx, "key", 1, 20*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	result, err := store.Take(ctx, "key", 1, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != 0 {
+		t.Errorf("got %+v after the window, want the first request of a new one", result)
+	}
+}
+
+func TestMemoryStoreSweepsExpiredWindows(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	_, err := store.Take(ctx, "old", 1, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	store.lastSweep = time.Now().Add(-2 * sweepInterval)
+	_, err = store.Take(ctx, "new", 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.windows["old"]; ok {
+		t.Error("expired window was not swept")
+	}
+}
@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	Memory = "memory"
+	Redis  = "redis"
+)
+
+// Result is the state of a key after a request was counted against it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetIn   time.Duration
+}
+
+// Store counts requests per key in fixed windows. Take counts one request and
+// reports whether it still fits into limit for the current window.
+type Store interface {
+	Take(ctx context.Context, key string, limit int, window time.Duration) (*Result, error)
+}
+
+func newResult(count, limit int, resetIn time.Duration) *Result {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &Result{
+		Allowed:   count <= limit,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetIn:   resetIn,
+	}
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript counts a request and starts the window when the counter has no
+// expiry yet, in one round trip so concurrent replicas cannot lose it. A
+// counter left without expiry, say by a failed write, starts a new window
+// instead of blocking its key forever.
+var takeScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// RedisStore shares the counters between every replica using the same Redis.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore keeps its counters under prefix. Any go-redis client works,
+// including one pointed at an in-process Redis.
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit int, window time.Duration) (*Result, error) {
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	count, ttl := values[0], values[1]
+	return newResult(int(count), limit, time.Duration(ttl)*time.Millisecond), nil
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStore(client, "test:"), server
+}
+
+func TestRedisStoreTake(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		remaining int
+		allowed   bool
+	}{
+		{remaining: 2, allowed: true},
+		{remaining: 1, allowed: true},
+		{remaining: 0, allowed: true},
+		{remaining: 0, allowed: false},
+	}
+	for i, test := range tests {
+		result, err := store.Take(ctx, "key", 3, 10*time.Second)
+		if err != nil {
+			t.Fatalf("take %d: %v", i, err)
+		}
+		if result.Allowed != test.allowed || result.Remaining != test.remaining || result.Limit != 3 {
+			t.Errorf("take %d: got %+v, want allowed %t remaining %d", i, result, test.allowed, test.remaining)
+		}
+		if result.ResetIn <= 0 || result.ResetIn > 10*time.Second {
+			t.Errorf("take %d: reset in %s, want within the window", i, result.ResetIn)
+		}
+	}
+
+	if ttl := server.TTL("test:key"); ttl != 10*time.Second {
+		t.Errorf("counter expires in %s, want 10s", ttl)
+	}
+}
+
+func TestRedisStoreTakeResetsAfterWindow(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := store.Take(ctx, "key", 1, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server.FastForward(time.Second)
+	result, err := store.Take(ctx, "key", 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != 0 {
+		t.Errorf("got %+v after the window, want the first request of a new one", result)
+	}
+}
+
+func TestRedisStoreTakeRestoresMissingExpiry(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	err := server.Set("test:key", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.Take(context.Background(), "key", 3, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.ResetIn != 10*time.Second {
+		t.Errorf("got %+v, want rejected with a reset in 10s", result)
+	}
+	if ttl := server.TTL("test:key"); ttl != 10*time.Second {
+		t.Errorf("counter expires in %s, want 10s", ttl)
+	}
+}
+
+func TestRedisStoreTakeError(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	server.Close()
+
+	_, err := store.Take(context.Background(), "key", 1, time.Second)
+	if err == nil {
+		t.Error("got no error from a stopped server")
+	}
+}
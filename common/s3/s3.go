@@ -1,84 +1,303 @@
-package s3
-
-import (
-	"bytes"
-	"context"
-	"fmt"
-	"time"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/sirupsen/logrus"
-)
-
-type S3Client struct {
-	AccessKeyID     string
-	SecretAccessKey string
-	Region          string
-	BucketName      string
-}
-
-type IS3Client interface {
-	UploadFile(context.Context, string, []byte) (string, error)
-}
-
-func NewS3Client(accessKeyID, secretAccessKey, region, bucketName string) IS3Client {
-	return &S3Client{
-		AccessKeyID:     accessKeyID,
-		SecretAccessKey: secretAccessKey,
-		Region:          region,
-		BucketName:      bucketName,
-	}
-}
-
-func (s *S3Client) createClient() (*s3.S3, error) {
-	sess, err := session.NewSession(&aws.Config{
-		Region:      aws.String(s.Region),
-		Credentials: credentials.NewStaticCredentials(s.AccessKeyID, s.SecretAccessKey, ""),
-	})
-	if err != nil {
-		logrus.Errorf("Failed to create AWS session: %v", err)
-		return nil, err
-	}
-
-	return s3.New(sess), nil
-}
-
-func (s *S3Client) UploadFile(ctx context.Context, fileName string, data []byte) (string, error) {
-	var (
-		contentType      = "application/octet-stream"
-		timeoutInSeconds = 60
-	)
-
-	client, err := s.createClient()
-	if err != nil {
-		logrus.Errorf("Failed to create S3 client: %v", err)
-		return "", err
-	}
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
-	defer cancel()
-
-	// Prepare the upload parameters
-	params := &s3.PutObjectInput{
-		Bucket:        aws.String(s.BucketName),
-		Key:           aws.String(fileName),
-		Body:          bytes.NewReader(data),
-		ContentLength: aws.Int64(int64(len(data))),
-		ContentType:   aws.String(contentType),
-	}
-
-	// Upload the file
-	_, err = client.PutObjectWithContext(ctx, params)
-	if err != nil {
-		logrus.Errorf("Failed to upload file to S3: %v", err)
-		return "", err
-	}
-
-	// Generate the URL to the object
-	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.BucketName, s.Region, fileName)
-	return url, nil
-}
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"field-service/common/storage"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultRegion is signed with when an S3-compatible store such as MinIO is
+// configured without a region.
+const defaultRegion = "us-east-1"
+
+type S3Client struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Region          string
+	BucketName      string
+	Endpoint        string
+	ForcePathStyle  bool
+	PublicBaseURL   string
+
+	client *s3.S3
+}
+
+type Option func(*S3Client)
+
+func NewS3Client(accessKeyID, secretAccessKey, region, bucketName string, options ...Option) storage.IStorage {
+	s3Client := &S3Client{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		Region:          region,
+		BucketName:      bucketName,
+	}
+
+	for _, option := range options {
+		option(s3Client)
+	}
+
+	if s3Client.Region == "" && s3Client.Endpoint != "" {
+		s3Client.Region = defaultRegion
+	}
+
+	// The session only fails on an invalid configuration, so it is created
+	// once here and shared by every request.
+	client, err := s3Client.createClient()
+	if err != nil {
+		panic(err)
+	}
+	s3Client.client = client
+
+	return s3Client
+}
+
+// WithEndpoint points the client at an S3-compatible store instead of AWS.
+func WithEndpoint(endpoint string) Option {
+	return func(s *S3Client) {
+		s.Endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithForcePathStyle addresses objects as endpoint/bucket/key rather than
+// bucket.endpoint/key, which most self-hosted stores require.
+func WithForcePathStyle(forcePathStyle bool) Option {
+	return func(s *S3Client) {
+		s.ForcePathStyle = forcePathStyle
+	}
+}
+
+// WithPublicBaseURL sets the URL objects are served from, e.g. a CDN or a
+// reverse proxy in front of the bucket. Object URLs are base URL + "/" + key.
+func WithPublicBaseURL(publicBaseURL string) Option {
+	return func(s *S3Client) {
+		s.PublicBaseURL = strings.TrimSuffix(publicBaseURL, "/")
+	}
+}
+
+func (s *S3Client) createClient() (*s3.S3, error) {
+	awsConfig := &aws.Config{
+		Region:           aws.String(s.Region),
+		Credentials:      credentials.NewStaticCredentials(s.AccessKeyID, s.SecretAccessKey, ""),
+		S3ForcePathStyle: aws.Bool(s.ForcePathStyle),
+	}
+	if s.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(s.Endpoint)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		logrus.Errorf("Failed to create AWS session: %v", err)
+		return nil, err
+	}
+
+	return s3.New(sess), nil
+}
+
+func (s *S3Client) UploadFile(ctx context.Context, fileName, contentType string, data []byte) (string, error) {
+	timeoutInSeconds := 60
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	// Prepare the upload parameters
+	params := &s3.PutObjectInput{
+		Bucket:        aws.String(s.BucketName),
+		Key:           aws.String(fileName),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+		ContentType:   aws.String(contentType),
+	}
+
+	// Upload the file
+	_, err := s.client.PutObjectWithContext(ctx, params)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to upload file to S3: %v", err)
+		return "", err
+	}
+
+	// Generate the URL to the object
+	url := s.ObjectURL(fileName)
+	return url, nil
+}
+
+func (s *S3Client) ObjectURL(fileName string) string {
+	return fmt.Sprintf("%s%s", s.baseURL(), fileName)
+}
+
+func (s *S3Client) baseURL() string {
+	if s.PublicBaseURL != "" {
+		return fmt.Sprintf("%s/", s.PublicBaseURL)
+	}
+
+	if s.Endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", s.BucketName, s.Region)
+	}
+
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || s.ForcePathStyle || endpoint.Host == "" {
+		return fmt.Sprintf("%s/%s/", s.Endpoint, s.BucketName)
+	}
+
+	endpoint.Host = fmt.Sprintf("%s.%s", s.BucketName, endpoint.Host)
+	return fmt.Sprintf("%s/", strings.TrimSuffix(endpoint.String(), "/"))
+}
+
+func (s *S3Client) ObjectKey(fileURL string) (string, bool) {
+	return strings.CutPrefix(fileURL, s.baseURL())
+}
+
+func (s *S3Client) DeleteFile(ctx context.Context, fileName string) error {
+	timeoutInSeconds := 60
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to delete file from S3: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Client) ReadFile(ctx context.Context, fileName string) ([]byte, error) {
+	timeoutInSeconds := 60
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	output, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to get S3 object: %v", err)
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to read S3 object: %v", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *S3Client) SignedURL(ctx context.Context, fileName string, expiry time.Duration) (string, error) {
+	request, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(fileName),
+	})
+
+	url, err := request.Presign(expiry)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to sign S3 URL: %v", err)
+		return "", err
+	}
+	return url, nil
+}
+
+func (s *S3Client) Exists(ctx context.Context, fileName string) (bool, error) {
+	timeoutInSeconds := 60
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	_, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		var requestFailure awserr.RequestFailure
+		if errors.As(err, &requestFailure) && requestFailure.StatusCode() == http.StatusNotFound {
+			return false, nil
+		}
+
+		logrus.WithContext(ctx).Errorf("Failed to head S3 object: %v", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// PresignUpload signs a PUT of exactly size bytes of contentType; S3 rejects
+// uploads whose Content-Type or Content-Length differ from the signed ones.
+func (s *S3Client) PresignUpload(
+	ctx context.Context,
+	fileName, contentType string,
+	size int64,
+	expiry time.Duration,
+) (*storage.PresignedUpload, error) {
+	request, _ := s.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:        aws.String(s.BucketName),
+		Key:           aws.String(fileName),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	})
+
+	url, signedHeaders, err := request.PresignRequest(expiry)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to presign S3 upload: %v", err)
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(signedHeaders))
+	for key, values := range signedHeaders {
+		key = http.CanonicalHeaderKey(key)
+		if key == "Host" || len(values) == 0 {
+			continue
+		}
+		headers[key] = values[0]
+	}
+
+	return &storage.PresignedUpload{
+		URL:       url,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		ExpiresAt: time.Now().Add(expiry),
+	}, nil
+}
+
+// List returns every object whose key starts with prefix.
+func (s *S3Client) List(ctx context.Context, prefix string) ([]storage.Object, error) {
+	objects := make([]storage.Object, 0)
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.BucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			objects = append(objects, storage.Object{
+				Key:       aws.StringValue(object.Key),
+				UpdatedAt: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Failed to list S3 objects: %v", err)
+		return nil, err
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,140 @@
+package sorting
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	Asc  = "asc"
+	Desc = "desc"
+)
+
+// Columns maps the sort keys accepted from clients to the SQL columns they
+// order by. Only keys listed here can ever reach an ORDER BY clause.
+type Columns map[string]string
+
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+type Error struct {
+	Key     string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type Errors []*Error
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Spec returns the sort spec of a request. The sort parameter wins; the
+// legacy sortColumn/sortOrder pair is folded into the same "key:order" form,
+// defaulting to ascending when sortOrder is missing.
+func Spec(sort, sortColumn, sortOrder *string) string {
+	if sort != nil {
+		return *sort
+	}
+
+	if sortColumn == nil {
+		return ""
+	}
+
+	order := Asc
+	if sortOrder != nil && *sortOrder != "" {
+		order = *sortOrder
+	}
+	return fmt.Sprintf("%s:%s", *sortColumn, order)
+}
+
+// Parse reads a comma separated list such as "name:asc,createdAt:desc". The
+// order may be omitted and defaults to ascending. Unknown keys, bad orders and
+// repeated columns are all reported together as Errors.
+func Parse(spec string, columns Columns) ([]Order, error) {
+	var (
+		orders = make([]Order, 0)
+		errs   Errors
+		seen   = make(map[string]bool)
+	)
+
+	if strings.TrimSpace(spec) == "" {
+		return orders, nil
+	}
+
+	for _, item := range strings.Split(spec, ",") {
+		key, order, _ := strings.Cut(strings.TrimSpace(item), ":")
+		key = strings.TrimSpace(key)
+		order = strings.ToLower(strings.TrimSpace(order))
+
+		column, ok := columns[key]
+		if !ok {
+			errs = append(errs, &Error{
+				Key:     key,
+				Message: fmt.Sprintf("sort key %q is not supported", key),
+			})
+			continue
+		}
+
+		if order == "" {
+			order = Asc
+		}
+
+		if order != Asc && order != Desc {
+			errs = append(errs, &Error{
+				Key:     key,
+				Message: fmt.Sprintf("sort order %q of %s must be asc or desc", order, key),
+			})
+			continue
+		}
+
+		// Aliases of a key order by the same column and repeat it too.
+		if seen[column] {
+			errs = append(errs, &Error{
+				Key:     key,
+				Message: fmt.Sprintf("sort key %s is repeated", key),
+			})
+			continue
+		}
+		seen[column] = true
+
+		orders = append(orders, Order{
+			Column: column,
+			Desc:   order == Desc,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return orders, nil
+}
+
+// Scope orders a query by orders, or by fallback when there are none.
+func Scope(orders []Order, fallback Order) func(*gorm.DB) *gorm.DB {
+	if len(orders) == 0 {
+		orders = []Order{fallback}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		for _, order := range orders {
+			db = db.Order(clause.OrderByColumn{
+				Column: clause.Column{Name: order.Column, Raw: true},
+				Desc:   order.Desc,
+			})
+		}
+		return db
+	}
+}
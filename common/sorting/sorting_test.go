@@ -0,0 +1,88 @@
+package sorting
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSpec(t *testing.T) {
+	value := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		sort       *string
+		sortColumn *string
+		sortOrder  *string
+		want       string
+	}{
+		{name: "none", want: ""},
+		{name: "sort", sort: value("name:desc"), sortColumn: value("code"), want: "name:desc"},
+		{name: "legacy", sortColumn: value("code"), sortOrder: value("desc"), want: "code:desc"},
+		{name: "legacy without order", sortColumn: value("code"), want: "code:asc"},
+		{name: "legacy with empty order", sortColumn: value("code"), sortOrder: value(""), want: "code:asc"},
+		{name: "order alone", sortOrder: value("desc"), want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Spec(test.sort, test.sortColumn, test.sortOrder); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	columns := Columns{
+		"name":       "fields.name",
+		"createdAt":  "fields.created_at",
+		"created_at": "fields.created_at",
+	}
+
+	tests := []struct {
+		name     string
+		spec     string
+		want     []Order
+		wantKeys []string
+	}{
+		{name: "empty", spec: " ", want: []Order{}},
+		{name: "default order", spec: "name", want: []Order{{Column: "fields.name"}}},
+		{
+			name: "several keys",
+			spec: "name:ASC, createdAt:desc",
+			want: []Order{{Column: "fields.name"}, {Column: "fields.created_at", Desc: true}},
+		},
+		{name: "unknown key", spec: "fields.name", wantKeys: []string{"fields.name"}},
+		{name: "bad order", spec: "name:up", wantKeys: []string{"name"}},
+		{name: "alias", spec: "created_at:desc", want: []Order{{Column: "fields.created_at", Desc: true}}},
+		{name: "repeated key", spec: "name,name:desc", wantKeys: []string{"name"}},
+		{name: "repeated through alias", spec: "createdAt,created_at", wantKeys: []string{"created_at"}},
+		{name: "every error", spec: "price,name:up,createdAt,createdAt", wantKeys: []string{"price", "name", "createdAt"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.spec, columns)
+			if test.wantKeys == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, test.want) {
+					t.Errorf("got %+v, want %+v", got, test.want)
+				}
+				return
+			}
+
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("got %v, want Errors", err)
+			}
+			keys := make([]string, 0, len(errs))
+			for _, e := range errs {
+				keys = append(keys, e.Key)
+			}
+			if !reflect.DeepEqual(keys, test.wantKeys) {
+				t.Errorf("got errors for %v, want %v", keys, test.wantKeys)
+			}
+		})
+	}
+}
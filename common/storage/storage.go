@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	S3    = "s3"
+	GCS   = "gcs"
+	Local = "local"
+)
+
+const (
+	URLPublic = "public"
+	URLSigned = "signed"
+	URLCDN    = "cdn"
+)
+
+// IStorage is implemented by every storage driver. Objects are addressed by
+// key; UploadFile and ObjectURL return the URL an object is served from and
+// ObjectKey maps such a URL back to its key.
+type IStorage interface {
+	UploadFile(context.Context, string, string, []byte) (string, error)
+	DeleteFile(context.Context, string) error
+	ReadFile(context.Context, string) ([]byte, error)
+	SignedURL(context.Context, string, time.Duration) (string, error)
+	PresignUpload(context.Context, string, string, int64, time.Duration) (*PresignedUpload, error)
+	Exists(context.Context, string) (bool, error)
+	List(context.Context, string) ([]Object, error)
+	ObjectURL(string) string
+	ObjectKey(string) (string, bool)
+}
+
+// Object is an entry returned by List.
+type Object struct {
+	Key       string
+	UpdatedAt time.Time
+}
+
+// PresignedUpload lets a client upload one object straight to storage. The
+// request must use Method and send every header in Headers unchanged.
+type PresignedUpload struct {
+	URL       string
+	Method    string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,140 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"field-service/constants"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRequestIDLength caps request IDs taken from callers.
+const maxRequestIDLength = 128
+
+// Trace correlates a request across services. RequestID travels as
+// x-request-id; TraceID, SpanID and Flags follow W3C trace context, where
+// SpanID is this service's span.
+type Trace struct {
+	RequestID string
+	TraceID   string
+	SpanID    string
+	Flags     string
+}
+
+func randomHex(size int) string {
+	data := make([]byte, size)
+	_, _ = rand.Read(data)
+	return hex.EncodeToString(data)
+}
+
+func isHex(value string, size int) bool {
+	_, err := hex.DecodeString(value)
+	return len(value) == size && err == nil && strings.ToLower(value) == value
+}
+
+func isZero(value string) bool {
+	return strings.Trim(value, "0") == ""
+}
+
+// parseTraceparent returns the trace ID and flags of a traceparent header.
+// Versions after 00 are read as far as 00 goes.
+func parseTraceparent(traceparent string) (string, string, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return "", "", false
+	}
+
+	traceID, parentID, flags := parts[1], parts[2], parts[3]
+	if !isHex(traceID, 32) || !isHex(parentID, 16) || !isHex(flags, 2) || isZero(traceID) || isZero(parentID) {
+		return "", "", false
+	}
+	return traceID, flags, true
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// New continues the trace of the caller, starting a new span, or starts a new
+// trace. Callers sending only a traceparent get its trace ID as request ID.
+func New(requestID, traceparent string) Trace {
+	traceID, flags, ok := parseTraceparent(traceparent)
+	if !ok {
+		traceID, flags = randomHex(16), "01"
+	}
+
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+		if ok {
+			requestID = traceID
+		}
+	}
+
+	return Trace{
+		RequestID: requestID,
+		TraceID:   traceID,
+		SpanID:    randomHex(8),
+		Flags:     flags,
+	}
+}
+
+func (t Trace) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%s", t.TraceID, t.SpanID, t.Flags)
+}
+
+func WithContext(ctx context.Context, t Trace) context.Context {
+	return context.WithValue(ctx, constants.Trace, t)
+}
+
+func FromContext(ctx context.Context) (Trace, bool) {
+	if ctx == nil {
+		return Trace{}, false
+	}
+	t, ok := ctx.Value(constants.Trace).(Trace)
+	return t, ok
+}
+
+// Inject forwards the trace of ctx on an outbound request.
+func Inject(ctx context.Context, header http.Header) {
+	t, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	header.Set(constants.XRequestID, t.RequestID)
+	header.Set(constants.Traceparent, t.Traceparent())
+}
+
+// Hook adds the trace of the entry's context to log entries made with
+// logrus.WithContext.
+type Hook struct{}
+
+func (Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (Hook) Fire(entry *logrus.Entry) error {
+	t, ok := FromContext(entry.Context)
+	if !ok {
+		return nil
+	}
+
+	entry.Data["request_id"] = t.RequestID
+	entry.Data["trace_id"] = t.TraceID
+	entry.Data["span_id"] = t.SpanID
+	return nil
+}
@@ -0,0 +1,81 @@
+package trace
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentID = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		wantTraceID string
+		wantFlags   string
+		wantOK      bool
+	}{
+		{name: "sampled", traceparent: "00-" + traceID + "-" + parentID + "-01",
+			wantTraceID: traceID, wantFlags: "01", wantOK: true},
+		{name: "not sampled", traceparent: " 00-" + traceID + "-" + parentID + "-00 ",
+			wantTraceID: traceID, wantFlags: "00", wantOK: true},
+		{name: "future version with extra fields", traceparent: "01-" + traceID + "-" + parentID + "-01-extra",
+			wantTraceID: traceID, wantFlags: "01", wantOK: true},
+		{name: "empty", traceparent: ""},
+		{name: "version ff", traceparent: "ff-" + traceID + "-" + parentID + "-01"},
+		{name: "version 00 with extra fields", traceparent: "00-" + traceID + "-" + parentID + "-01-extra"},
+		{name: "upper case", traceparent: "00-" + strings.ToUpper(traceID) + "-" + parentID + "-01"},
+		{name: "short trace id", traceparent: "00-" + traceID[:30] + "-" + parentID + "-01"},
+		{name: "short parent id", traceparent: "00-" + traceID + "-" + parentID[:14] + "-01"},
+		{name: "zero trace id", traceparent: "00-" + strings.Repeat("0", 32) + "-" + parentID + "-01"},
+		{name: "zero parent id", traceparent: "00-" + traceID + "-" + strings.Repeat("0", 16) + "-01"},
+		{name: "bad flags", traceparent: "00-" + traceID + "-" + parentID + "-1"},
+		{name: "not hex", traceparent: "00-" + strings.Repeat("g", 32) + "-" + parentID + "-01"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotTraceID, gotFlags, ok := parseTraceparent(test.traceparent)
+			if ok != test.wantOK || gotTraceID != test.wantTraceID || gotFlags != test.wantFlags {
+				t.Errorf("got (%q, %q, %t), want (%q, %q, %t)",
+					gotTraceID, gotFlags, ok, test.wantTraceID, test.wantFlags, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     string
+		traceparent   string
+		wantRequestID string
+		wantTraceID   string
+	}{
+		{name: "from caller", requestID: "req-1", traceparent: "00-" + traceID + "-" + parentID + "-01",
+			wantRequestID: "req-1", wantTraceID: traceID},
+		{name: "request id from trace", traceparent: "00-" + traceID + "-" + parentID + "-01",
+			wantRequestID: traceID, wantTraceID: traceID},
+		{name: "invalid request id", requestID: "bad\nid", traceparent: "00-" + traceID + "-" + parentID + "-01",
+			wantRequestID: traceID, wantTraceID: traceID},
+		{name: "new trace", requestID: "req-1", wantRequestID: "req-1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := New(test.requestID, test.traceparent)
+			if got.RequestID != test.wantRequestID {
+				t.Errorf("got request id %q, want %q", got.RequestID, test.wantRequestID)
+			}
+			if test.wantTraceID != "" && got.TraceID != test.wantTraceID {
+				t.Errorf("got trace id %q, want %q", got.TraceID, test.wantTraceID)
+			}
+
+			traceID, flags, ok := parseTraceparent(got.Traceparent())
+			if !ok || traceID != got.TraceID || flags != got.Flags || got.SpanID == parentID {
+				t.Errorf("got traceparent %q, want a new span of trace %s", got.Traceparent(), got.TraceID)
+			}
+		})
+	}
+}
@@ -1,173 +1,253 @@
-package util
-
-import (
-	"crypto/sha256"
-	"encoding/hex"
-	"fmt"
-	"math"
-	"os"
-	"reflect"
-	"strconv"
-	"strings"
-
-	"github.com/dustin/go-humanize"
-	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
-	_ "github.com/spf13/viper/remote"
-)
-
-type PaginationParam struct {
-	Count int64       `json:"count"`
-	Page  int         `json:"page"`
-	Limit int         `json:"limit"`
-	Data  interface{} `json:"data"`
-}
-
-type PaginationResult struct {
-	TotalPage    int         `json:"totalPage"`
-	TotalData    int64       `json:"totalData"`
-	NextPage     *int        `json:"nextPage"`
-	PreviousPage *int        `json:"previousPage"`
-	Page         int         `json:"page"`
-	Limit        int         `json:"limit"`
-	Data         interface{} `json:"data"`
-}
-
-func GeneratePagination(params PaginationParam) PaginationResult {
-	totalPage := int(math.Ceil(float64(params.Count) / float64(params.Limit)))
-
-	var (
-		nextPage     int
-		previousPage int
-	)
-
-	if params.Page < totalPage {
-		nextPage = params.Page + 1
-	}
-
-	if params.Page > 1 {
-		previousPage = params.Page - 1
-	}
-
-	result := PaginationResult{
-		TotalPage:    totalPage,
-		TotalData:    params.Count,
-		NextPage:     &nextPage,
-		PreviousPage: &previousPage,
-		Page:         params.Page,
-		Limit:        params.Limit,
-		Data:         params.Data,
-	}
-
-	return result
-}
-
-func GenerateSHA256(inputString string) string {
-	hash := sha256.New()
-	hash.Write([]byte(inputString))
-	hashBytes := hash.Sum(nil)
-	hashString := hex.EncodeToString(hashBytes)
-	return hashString
-}
-
-func RupiahFormat(amount *float64) string {
-	stringValue := "0"
-	if amount != nil {
-		humanizeValue := humanize.CommafWithDigits(*amount, 0)
-		stringValue = strings.ReplaceAll(humanizeValue, ",", ".")
-	}
-	return fmt.Sprintf("Rp %s", stringValue)
-}
-
-func BindFromJson(dest any, filename, path string) error {
-	v := viper.New()
-
-	v.SetConfigType("json")
-	v.AddConfigPath(path)
-	v.SetConfigName(filename)
-
-	err := v.ReadInConfig()
-	if err != nil {
-		return err
-	}
-
-	err = v.Unmarshal(&dest)
-	if err != nil {
-		logrus.Errorf("failed to unmarshal: %v", err)
-		return err
-	}
-
-	return nil
-}
-
-func SetEnvFromConsulKV(v *viper.Viper) error {
-	env := make(map[string]any)
-
-	err := v.Unmarshal(&env)
-	if err != nil {
-		logrus.Errorf("failed to unmarshal: %v", err)
-		return err
-	}
-
-	for k, v := range env {
-		var (
-			valOf = reflect.ValueOf(v)
-			val   string
-		)
-
-		switch valOf.Kind() {
-		case reflect.String:
-			val = valOf.String()
-		case reflect.Int:
-			val = strconv.Itoa(int(valOf.Int()))
-		case reflect.Uint:
-			val = strconv.Itoa(int(valOf.Uint()))
-		case reflect.Float32:
-			val = strconv.Itoa(int(valOf.Float()))
-		case reflect.Float64:
-			val = strconv.Itoa(int(valOf.Float()))
-		case reflect.Bool:
-			val = strconv.FormatBool(valOf.Bool())
-		}
-
-		err = os.Setenv(k, val)
-		if err != nil {
-			logrus.Errorf("failed to set env: %v", err)
-			return err
-		}
-	}
-
-	return nil
-}
-
-func BindFromConsul(dest any, endPoint, path string) error {
-	v := viper.New()
-
-	v.SetConfigType("json")
-	err := v.AddRemoteProvider("consul", endPoint, path)
-
-	if err != nil {
-		logrus.Errorf("failed to add remote provider: %v", err)
-		return err
-	}
-
-	err = v.ReadRemoteConfig()
-	if err != nil {
-		logrus.Errorf("failed to read remote config: %v", err)
-		return err
-	}
-
-	err = v.Unmarshal(&dest)
-	if err != nil {
-		logrus.Errorf("failed to unmarshal: %v", err)
-		return err
-	}
-
-	err = SetEnvFromConsulKV(v)
-	if err != nil {
-		logrus.Errorf("failed to set env from consul kv: %v", err)
-		return err
-	}
-
-	return nil
-}
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	_ "github.com/spf13/viper/remote"
+	"gorm.io/gorm"
+)
+
+type PaginationParam struct {
+	Count int64       `json:"count"`
+	Page  int         `json:"page"`
+	Limit int         `json:"limit"`
+	Data  interface{} `json:"data"`
+}
+
+type PaginationResult struct {
+	TotalPage    int         `json:"totalPage"`
+	TotalData    int64       `json:"totalData"`
+	NextPage     *int        `json:"nextPage"`
+	PreviousPage *int        `json:"previousPage"`
+	Page         int         `json:"page"`
+	Limit        int         `json:"limit"`
+	Data         interface{} `json:"data"`
+	NextCursor   *string     `json:"nextCursor,omitempty"`
+}
+
+type CursorPaginationParam struct {
+	Limit      int         `json:"limit"`
+	NextCursor *Cursor     `json:"nextCursor"`
+	Data       interface{} `json:"data"`
+}
+
+// Cursor is the keyset position of the last row of a page. Rows are walked
+// newest first by (created_at, id), so the next page starts strictly after it.
+type Cursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uint      `json:"i"`
+}
+
+// KeysetScope orders the rows of table for cursor pagination and, given the
+// cursor of the previous page, skips everything up to it. Both match the
+// (created_at, id) index of table, read backwards.
+func KeysetScope(table string, after *Cursor) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Order(fmt.Sprintf("%[1]s.created_at desc, %[1]s.id desc", table))
+		if after == nil {
+			return db
+		}
+		return db.Where(fmt.Sprintf("(%[1]s.created_at, %[1]s.id) < (?, ?)", table), after.CreatedAt, after.ID)
+	}
+}
+
+func GeneratePagination(params PaginationParam) PaginationResult {
+	totalPage := int(math.Ceil(float64(params.Count) / float64(params.Limit)))
+
+	var (
+		nextPage     int
+		previousPage int
+	)
+
+	if params.Page < totalPage {
+		nextPage = params.Page + 1
+	}
+
+	if params.Page > 1 {
+		previousPage = params.Page - 1
+	}
+
+	result := PaginationResult{
+		TotalPage:    totalPage,
+		TotalData:    params.Count,
+		NextPage:     &nextPage,
+		PreviousPage: &previousPage,
+		Page:         params.Page,
+		Limit:        params.Limit,
+		Data:         params.Data,
+	}
+
+	return result
+}
+
+func GenerateCursorPagination(params CursorPaginationParam) (PaginationResult, error) {
+	result := PaginationResult{
+		Limit: params.Limit,
+		Data:  params.Data,
+	}
+
+	if params.NextCursor != nil {
+		nextCursor, err := EncodeCursor(*params.NextCursor)
+		if err != nil {
+			return PaginationResult{}, err
+		}
+		result.NextCursor = &nextCursor
+	}
+
+	return result, nil
+}
+
+func EncodeCursor(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor. An empty string is
+// the first page and decodes to nil.
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor Cursor
+	err = json.Unmarshal(payload, &cursor)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.CreatedAt.IsZero() {
+		return nil, errors.New("cursor without created at")
+	}
+	return &cursor, nil
+}
+
+func GenerateSHA256(inputString string) string {
+	hash := sha256.New()
+	hash.Write([]byte(inputString))
+	hashBytes := hash.Sum(nil)
+	hashString := hex.EncodeToString(hashBytes)
+	return hashString
+}
+
+func RupiahFormat(amount *float64) string {
+	stringValue := "0"
+	if amount != nil {
+		humanizeValue := humanize.CommafWithDigits(*amount, 0)
+		stringValue = strings.ReplaceAll(humanizeValue, ",", ".")
+	}
+	return fmt.Sprintf("Rp %s", stringValue)
+}
+
+func BindFromJson(dest any, filename, path string) error {
+	v := viper.New()
+
+	v.SetConfigType("json")
+	v.AddConfigPath(path)
+	v.SetConfigName(filename)
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	err = v.Unmarshal(&dest)
+	if err != nil {
+		logrus.Errorf("failed to unmarshal: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func SetEnvFromConsulKV(v *viper.Viper) error {
+	env := make(map[string]any)
+
+	err := v.Unmarshal(&env)
+	if err != nil {
+		logrus.Errorf("failed to unmarshal: %v", err)
+		return err
+	}
+
+	for k, v := range env {
+		var (
+			valOf = reflect.ValueOf(v)
+			val   string
+		)
+
+		switch valOf.Kind() {
+		case reflect.String:
+			val = valOf.String()
+		case reflect.Int:
+			val = strconv.Itoa(int(valOf.Int()))
+		case reflect.Uint:
+			val = strconv.Itoa(int(valOf.Uint()))
+		case reflect.Float32:
+			val = strconv.Itoa(int(valOf.Float()))
+		case reflect.Float64:
+			val = strconv.Itoa(int(valOf.Float()))
+		case reflect.Bool:
+			val = strconv.FormatBool(valOf.Bool())
+		}
+
+		err = os.Setenv(k, val)
+		if err != nil {
+			logrus.Errorf("failed to set env: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func BindFromConsul(dest any, endPoint, path string) error {
+	v := viper.New()
+
+	v.SetConfigType("json")
+	err := v.AddRemoteProvider("consul", endPoint, path)
+
+	if err != nil {
+		logrus.Errorf("failed to add remote provider: %v", err)
+		return err
+	}
+
+	err = v.ReadRemoteConfig()
+	if err != nil {
+		logrus.Errorf("failed to read remote config: %v", err)
+		return err
+	}
+
+	err = v.Unmarshal(&dest)
+	if err != nil {
+		logrus.Errorf("failed to unmarshal: %v", err)
+		return err
+	}
+
+	err = SetEnvFromConsulKV(v)
+	if err != nil {
+		logrus.Errorf("failed to set env from consul kv: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, 5, 1, 10, 30, 0, 123456789, time.UTC), ID: 42}
+
+	encoded, err := EncodeCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodeCursor(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != cursor.ID || !decoded.CreatedAt.Equal(cursor.CreatedAt) {
+		t.Errorf("got %+v, want %+v", decoded, cursor)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "first page", value: "", wantNil: true},
+		{name: "not base64", value: "!!!", wantErr: true},
+		{name: "padded base64", value: base64.URLEncoding.EncodeToString([]byte(`{"c":"2024-05-01T10:30:00Z","i":1}`)), wantErr: true},
+		{name: "not json", value: base64.RawURLEncoding.EncodeToString([]byte("cursor")), wantErr: true},
+		{name: "wrong types", value: base64.RawURLEncoding.EncodeToString([]byte(`{"i":"1"}`)), wantErr: true},
+		{name: "without created at", value: base64.RawURLEncoding.EncodeToString([]byte(`{"i":1}`)), wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cursor, err := DecodeCursor(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+			if (cursor == nil) != (test.wantNil || test.wantErr) {
+				t.Errorf("got cursor %+v", cursor)
+			}
+		})
+	}
+}
@@ -1,74 +1,353 @@
-package config
-
-import (
-	"field-service/common/util"
-	"os"
-
-	"github.com/sirupsen/logrus"
-	_ "github.com/spf13/viper/remote"
-)
-
-var Config AppConfig
-
-type AppConfig struct {
-	Port                  int             `json:"port"`
-	AppName               string          `json:"appName"`
-	AppEnv                string          `json:"appEnv"`
-	SignatureKey          string          `json:"signatureKey"`
-	Database              Database        `json:"database"`
-	RateLimiterMaxRequest float64         `json:"rateLimiterMaxRequest"`
-	RateLimiterTimeSecond int             `json:"rateLimiterTimeSecond"`
-	InternalService       InternalService `json:"internalService"`
-
-	//S3 Config
-	S3AccessKeyID     string `json:"s3AccessKeyID"`
-	S3SecretAccessKey string `json:"s3SecretAccessKey"`
-	S3Region          string `json:"s3Region"`
-	S3BucketName      string `json:"s3BucketName"`
-
-	// GCS Config
-	GCSType                    string `json:"gcsType"`
-	GCSProjectID               string `json:"gcsProjectID"`
-	GCSPrivateKeyID            string `json:"gcsPrivateKeyID"`
-	GCSPrivateKey              string `json:"gcsPrivateKey"`
-	GCSClientEmail             string `json:"gcsClientEmail"`
-	GCSClientID                string `json:"gcsClientID"`
-	GCSAuthURI                 string `json:"gcsAuthURI"`
-	GCSTokenURI                string `json:"gcsTokenURI"`
-	GCSAuthProviderX509CertURL string `json:"gcsAuthProviderX509CertURL"`
-	GCSClientX509CertURL       string `json:"gcsClientX509CertURL"`
-	GCSUniverseDomain          string `json:"gcsUniverseDomain"`
-	GCSBucketName              string `json:"gcsBucketName"`
-}
-
-type Database struct {
-	Host                  string `json:"host"`
-	Port                  int    `json:"port"`
-	Name                  string `json:"name"`
-	Username              string `json:"username"`
-	Password              string `json:"password"`
-	MaxOpenConnections    int    `json:"maxOpenConnections"`
-	MaxLifeTimeConnection int    `json:"maxLifeTimeConnection"`
-	MaxIdleConnections    int    `json:"maxIdleConnections"`
-	MaxIdleTime           int    `json:"maxIdleTime"`
-}
-
-type User struct {
-	Host         string `json:"host"`
-	SignatureKey string `json:"signatureKey"`
-}
-
-type InternalService struct {
-	User User `json:"user"`
-}
-
-func Init() {
-	err := util.BindFromJson(&Config, "config.json", ".")
-	if err != nil {
-		logrus.Infof("failed to bind config: %v", err)
-		err = util.BindFromConsul(&Config, os.Getenv("CONSUL_HTTP_URL"), os.Getenv("CONSUL_HTTP_PATH"))
-		if err != nil {
-			panic(err)
-		}
-	}
-}
+package config
+
+import (
+	"errors"
+	"field-service/common/storage"
+	"field-service/common/util"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	_ "github.com/spf13/viper/remote"
+)
+
+var Config AppConfig
+
+type AppConfig struct {
+	Port                  int             `json:"port"`
+	AppName               string          `json:"appName"`
+	AppEnv                string          `json:"appEnv"`
+	SignatureKey          string          `json:"signatureKey"`
+	APIKey                APIKey          `json:"apiKey"`
+	Services              []Service       `json:"services"`
+	RoutePolicies         []RoutePolicy   `json:"routePolicies"`
+	Database              Database        `json:"database"`
+	RateLimiterMaxRequest float64         `json:"rateLimiterMaxRequest"`
+	RateLimiterTimeSecond int             `json:"rateLimiterTimeSecond"`
+	RateLimit             RateLimit       `json:"rateLimit"`
+	CORS                  CORS            `json:"cors"`
+	TrustedProxies        []string        `json:"trustedProxies"`
+	InternalService       InternalService `json:"internalService"`
+	Storage               Storage         `json:"storage"`
+	Log                   Log             `json:"log"`
+	Metrics               Metrics         `json:"metrics"`
+
+	// Deprecated: set storage.s3 instead. Read only where it is left empty.
+	S3AccessKeyID     string `json:"s3AccessKeyID"`
+	S3SecretAccessKey string `json:"s3SecretAccessKey"`
+	S3Region          string `json:"s3Region"`
+	S3BucketName      string `json:"s3BucketName"`
+
+	// Deprecated: set storage.gcs instead. Read only where it is left empty.
+	GCSType                    string `json:"gcsType"`
+	GCSProjectID               string `json:"gcsProjectID"`
+	GCSPrivateKeyID            string `json:"gcsPrivateKeyID"`
+	GCSPrivateKey              string `json:"gcsPrivateKey"`
+	GCSClientEmail             string `json:"gcsClientEmail"`
+	GCSClientID                string `json:"gcsClientID"`
+	GCSAuthURI                 string `json:"gcsAuthURI"`
+	GCSTokenURI                string `json:"gcsTokenURI"`
+	GCSAuthProviderX509CertURL string `json:"gcsAuthProviderX509CertURL"`
+	GCSClientX509CertURL       string `json:"gcsClientX509CertURL"`
+	GCSUniverseDomain          string `json:"gcsUniverseDomain"`
+	GCSBucketName              string `json:"gcsBucketName"`
+}
+
+// APIKey hardens the x-api-key check of incoming calls. Requests are rejected
+// when x-request-at is more than MaxSkewSecond off, and a x-nonce is accepted
+// only once; RequireNonce makes the nonce mandatory. AllowLegacy still accepts
+// signatures that cover only the service name and x-request-at, for callers
+// not yet signing the method, path and body. Bodies larger than MaxBodyBytes
+// are rejected before they are read for the signature. Nonces are kept in the
+// rate limit store, so with the "redis" store a nonce is used up on every
+// replica.
+//
+// Callers missing from Services sign with the shared SignatureKey and may call
+// every route. Restricting them is opt-in: when SharedKeyScopes is set, they
+// may only call the routes in it.
+// DisableSharedKey turns the shared key off, leaving only registered callers.
+type APIKey struct {
+	MaxSkewSecond    int      `json:"maxSkewSecond"`
+	RequireNonce     bool     `json:"requireNonce"`
+	AllowLegacy      bool     `json:"allowLegacy"`
+	MaxBodyBytes     int64    `json:"maxBodyBytes"`
+	DisableSharedKey bool     `json:"disableSharedKey"`
+	SharedKeyScopes  []string `json:"sharedKeyScopes"`
+}
+
+// Service is a caller allowed to sign requests as Name. Any of SignatureKeys
+// is accepted, so a new key can be added before the caller switches to it and
+// the old one removed afterwards. Scopes lists the routes it may call as
+// "METHOD /route", where METHOD may be "*" and a trailing "*" matches any
+// route with that prefix, e.g. "GET /api/v1/field/*". Callers missing from
+// Services are handled as configured in APIKey.
+type Service struct {
+	Name          string   `json:"name"`
+	SignatureKeys []string `json:"signatureKeys"`
+	Scopes        []string `json:"scopes"`
+}
+
+// RoutePolicy decides who may call the routes matching Route, written like a
+// service scope, e.g. "POST /api/v1/field/schedule/one-month". The first
+// matching policy applies. Every call needs a valid API key; Services, when
+// set, limits the callers by x-service-name. Token routes also need a bearer
+// token whose user has one of Roles, when set, and all of Permissions.
+// Without routePolicies, the defaults in routes/policy.go apply.
+type RoutePolicy struct {
+	Route       string   `json:"route"`
+	Token       bool     `json:"token"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	Services    []string `json:"services"`
+}
+
+// RateLimit counts requests in fixed windows, per instance with the "memory"
+// store (the default) or shared by every replica with "redis". Without Rules,
+// every IP may make rateLimiterMaxRequest requests per rateLimiterTimeSecond.
+// Client IPs are read from X-Forwarded-For only when the request comes from
+// one of trustedProxies, given as IPs or CIDRs.
+// The store also keeps the used x-nonce values, see APIKey.
+type RateLimit struct {
+	Store string          `json:"store"`
+	Redis RateLimitRedis  `json:"redis"`
+	Rules []RateLimitRule `json:"rules"`
+}
+
+type RateLimitRedis struct {
+	Address  string `json:"address"`
+	Password string `json:"password"`
+	DB       int    `json:"db"`
+	Prefix   string `json:"prefix"`
+}
+
+// RateLimitRule lets every caller make MaxRequest requests per WindowSecond on
+// the routes matching Route, written like a route policy. Key identifies the
+// caller by "user", "service" or "ip"; calls without a user count against
+// their service, and calls without a service against their IP. Every matching
+// rule applies, each with its own counters.
+type RateLimitRule struct {
+	Name         string `json:"name"`
+	Route        string `json:"route"`
+	Key          string `json:"key"`
+	MaxRequest   int    `json:"maxRequest"`
+	WindowSecond int    `json:"windowSecond"`
+}
+
+// CORS lets browsers on AllowedOrigins call the API. An origin may contain
+// one "*", e.g. "https://*.example.com", and "*" alone allows every origin.
+// Empty lists fall back to every origin, the methods the API serves and the
+// headers its callers sign with. AllowCredentials needs the origins listed:
+// the service does not start with it and the "*" origin.
+type CORS struct {
+	AllowedOrigins   []string `json:"allowedOrigins"`
+	AllowedMethods   []string `json:"allowedMethods"`
+	AllowedHeaders   []string `json:"allowedHeaders"`
+	ExposedHeaders   []string `json:"exposedHeaders"`
+	AllowCredentials bool     `json:"allowCredentials"`
+	MaxAgeSecond     int      `json:"maxAgeSecond"`
+}
+
+// Log sets the logrus level and format, "json" (the default) or "text".
+type Log struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// Metrics serves the Prometheus metrics on their own Port, 9102 when unset,
+// apart from the API, so they are reachable only where that port is. Disable
+// turns them off.
+type Metrics struct {
+	Port    int  `json:"port"`
+	Disable bool `json:"disable"`
+}
+
+type Database struct {
+	Host                  string `json:"host"`
+	Port                  int    `json:"port"`
+	Name                  string `json:"name"`
+	Username              string `json:"username"`
+	Password              string `json:"password"`
+	MaxOpenConnections    int    `json:"maxOpenConnections"`
+	MaxLifeTimeConnection int    `json:"maxLifeTimeConnection"`
+	MaxIdleConnections    int    `json:"maxIdleConnections"`
+	MaxIdleTime           int    `json:"maxIdleTime"`
+}
+
+// User configures the user service client. Zero values of TimeoutSecond,
+// Retry and CircuitBreaker keep the client defaults.
+type User struct {
+	Host           string             `json:"host"`
+	SignatureKey   string             `json:"signatureKey"`
+	TimeoutSecond  int                `json:"timeoutSecond"`
+	Retry          UserRetry          `json:"retry"`
+	CircuitBreaker UserCircuitBreaker `json:"circuitBreaker"`
+	JWT            UserJWT            `json:"jwt"`
+	Cache          UserCache          `json:"cache"`
+}
+
+type UserRetry struct {
+	MaxRetries         int `json:"maxRetries"`
+	BackoffMillisecond int `json:"backoffMillisecond"`
+}
+
+// UserCircuitBreaker stops calling the user service for CooldownSecond after
+// FailureThreshold lookups in a row failed.
+type UserCircuitBreaker struct {
+	FailureThreshold int `json:"failureThreshold"`
+	CooldownSecond   int `json:"cooldownSecond"`
+}
+
+// UserCache keeps up to Size users looked up from the user service for
+// TTLSecond, so repeated requests with one token cost a single call.
+type UserCache struct {
+	Enabled   bool `json:"enabled"`
+	TTLSecond int  `json:"ttlSecond"`
+	Size      int  `json:"size"`
+}
+
+// UserJWT verifies bearer tokens locally instead of asking the user service.
+// HS256 tokens are checked against Secret, RS256 tokens against the keys in
+// JWKSFile or JWKSURL, refetched every JWKSRefreshSecond. The user is read
+// from the UserClaim object, or from the top-level claims when it is empty.
+// With Fallback, tokens that fail local verification go to the user service.
+type UserJWT struct {
+	Enabled           bool   `json:"enabled"`
+	Algorithm         string `json:"algorithm"`
+	Secret            string `json:"secret"`
+	JWKSFile          string `json:"jwksFile"`
+	JWKSURL           string `json:"jwksURL"`
+	JWKSRefreshSecond int    `json:"jwksRefreshSecond"`
+	Issuer            string `json:"issuer"`
+	Audience          string `json:"audience"`
+	UserClaim         string `json:"userClaim"`
+	Fallback          bool   `json:"fallback"`
+}
+
+type InternalService struct {
+	User User `json:"user"`
+}
+
+// Storage selects the driver images are stored with: "s3" (the default),
+// "gcs" or "local".
+//
+// With StoreKeys, fields keep object keys instead of URLs so the bucket can
+// stay private. URLMode decides the image URLs in responses: "public" (the
+// default) serves objects straight from storage, "signed" issues GET URLs
+// valid for SignedURLExpirySecond (an hour when unset) and "cdn" prefixes
+// keys with CDNBaseURL, which it requires.
+// UploadConcurrency bounds the images of one request processed at a time.
+type Storage struct {
+	Driver                string       `json:"driver"`
+	UploadConcurrency     int          `json:"uploadConcurrency"`
+	StoreKeys             bool         `json:"storeKeys"`
+	URLMode               string       `json:"urlMode"`
+	SignedURLExpirySecond int          `json:"signedURLExpirySecond"`
+	CDNBaseURL            string       `json:"cdnBaseURL"`
+	S3                    S3Storage    `json:"s3"`
+	GCS                   GCSStorage   `json:"gcs"`
+	Local                 LocalStorage `json:"local"`
+}
+
+// S3Storage talks to AWS unless Endpoint points at an S3-compatible store
+// such as MinIO. PublicBaseURL overrides the URL returned for uploaded objects.
+type S3Storage struct {
+	AccessKeyID     string `json:"accessKeyID"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	Region          string `json:"region"`
+	BucketName      string `json:"bucketName"`
+	Endpoint        string `json:"endpoint"`
+	ForcePathStyle  bool   `json:"forcePathStyle"`
+	PublicBaseURL   string `json:"publicBaseURL"`
+}
+
+type GCSStorage struct {
+	Type                    string `json:"type"`
+	ProjectID               string `json:"projectID"`
+	PrivateKeyID            string `json:"privateKeyID"`
+	PrivateKey              string `json:"privateKey"`
+	ClientEmail             string `json:"clientEmail"`
+	ClientID                string `json:"clientID"`
+	AuthURI                 string `json:"authURI"`
+	TokenURI                string `json:"tokenURI"`
+	AuthProviderX509CertURL string `json:"authProviderX509CertURL"`
+	ClientX509CertURL       string `json:"clientX509CertURL"`
+	UniverseDomain          string `json:"universeDomain"`
+	BucketName              string `json:"bucketName"`
+}
+
+// LocalStorage keeps files under Path and serves them on Route, which must be
+// reachable at BaseURL. SignatureKey, which is required, signs presigned
+// uploads and the URLs of private files.
+type LocalStorage struct {
+	Path         string `json:"path"`
+	Route        string `json:"route"`
+	BaseURL      string `json:"baseURL"`
+	SignatureKey string `json:"signatureKey"`
+}
+
+func Init() {
+	err := util.BindFromJson(&Config, "config.json", ".")
+	if err != nil {
+		logrus.Infof("failed to bind config: %v", err)
+		err = util.BindFromConsul(&Config, os.Getenv("CONSUL_HTTP_URL"), os.Getenv("CONSUL_HTTP_PATH"))
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	Config.applyLegacyStorage()
+	err = Config.Storage.checkURLMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// checkURLMode rejects URL settings that would hand out broken image URLs
+// instead of failing on the first response.
+func (s *Storage) checkURLMode() error {
+	switch s.URLMode {
+	case "", storage.URLPublic, storage.URLSigned:
+		return nil
+	case storage.URLCDN:
+		if s.CDNBaseURL == "" {
+			return errors.New("storage.cdnBaseURL is required when storage.urlMode is cdn")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown storage.urlMode %q, use public, signed or cdn", s.URLMode)
+	}
+}
+
+// applyLegacyStorage fills the storage settings left empty from the top-level
+// s3* and gcs* keys they used to be read from, so older configs keep working.
+func (c *AppConfig) applyLegacyStorage() {
+	legacy := false
+	fallback := func(value *string, old string) {
+		if *value == "" && old != "" {
+			*value = old
+			legacy = true
+		}
+	}
+
+	fallback(&c.Storage.S3.AccessKeyID, c.S3AccessKeyID)
+	fallback(&c.Storage.S3.SecretAccessKey, c.S3SecretAccessKey)
+	fallback(&c.Storage.S3.Region, c.S3Region)
+	fallback(&c.Storage.S3.BucketName, c.S3BucketName)
+
+	fallback(&c.Storage.GCS.Type, c.GCSType)
+	fallback(&c.Storage.GCS.ProjectID, c.GCSProjectID)
+	fallback(&c.Storage.GCS.PrivateKeyID, c.GCSPrivateKeyID)
+	fallback(&c.Storage.GCS.PrivateKey, c.GCSPrivateKey)
+	fallback(&c.Storage.GCS.ClientEmail, c.GCSClientEmail)
+	fallback(&c.Storage.GCS.ClientID, c.GCSClientID)
+	fallback(&c.Storage.GCS.AuthURI, c.GCSAuthURI)
+	fallback(&c.Storage.GCS.TokenURI, c.GCSTokenURI)
+	fallback(&c.Storage.GCS.AuthProviderX509CertURL, c.GCSAuthProviderX509CertURL)
+	fallback(&c.Storage.GCS.ClientX509CertURL, c.GCSClientX509CertURL)
+	fallback(&c.Storage.GCS.UniverseDomain, c.GCSUniverseDomain)
+	fallback(&c.Storage.GCS.BucketName, c.GCSBucketName)
+
+	if legacy {
+		logrus.Warn("top-level s3* and gcs* config keys are deprecated, move them under storage")
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestCheckURLMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage Storage
+		wantErr bool
+	}{
+		{name: "default", storage: Storage{}},
+		{name: "public", storage: Storage{URLMode: "public"}},
+		{name: "signed", storage: Storage{URLMode: "signed"}},
+		{name: "cdn", storage: Storage{URLMode: "cdn", CDNBaseURL: "https://cdn.example.com"}},
+		{name: "cdn without base url", storage: Storage{URLMode: "cdn"}, wantErr: true},
+		{name: "unknown", storage: Storage{URLMode: "presigned"}, wantErr: true},
+		{name: "wrong case", storage: Storage{URLMode: "Signed"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.storage.checkURLMode()
+			if (err != nil) != test.wantErr {
+				t.Errorf("got %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
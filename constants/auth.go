@@ -1,5 +1,9 @@
 package constants
 
 const (
-	Token = "token"
+	Token     = "token"
+	User      = "user"
+	RequestID = "requestID"
+	Trace     = "trace"
+	RateLimit = "rateLimit"
 )
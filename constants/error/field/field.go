@@ -1,11 +1,23 @@
-package error
-
-import "errors"
-
-var (
-	ErrFieldNotFound = errors.New("field not found")
-)
-
-var FieldErrors = []error{
-	ErrFieldNotFound,
-}
+package error
+
+import "errors"
+
+var (
+	ErrFieldNotFound      = errors.New("field not found")
+	ErrFieldImageNotFound = errors.New("field image not found")
+	ErrInvalidImageOrder  = errors.New("invalid image order")
+	ErrInvalidUploadKey   = errors.New("invalid upload key")
+	ErrUploadNotFound     = errors.New("uploaded image not found")
+	ErrInvalidOwner       = errors.New("invalid field owner")
+	ErrUnresolvedImages   = errors.New("field images outside the configured storage")
+)
+
+var FieldErrors = []error{
+	ErrFieldNotFound,
+	ErrFieldImageNotFound,
+	ErrInvalidImageOrder,
+	ErrInvalidUploadKey,
+	ErrUploadNotFound,
+	ErrInvalidOwner,
+	ErrUnresolvedImages,
+}
@@ -1,13 +1,15 @@
-package error
-
-import "errors"
-
-var (
-	ErrFieldScheduleNotFound = errors.New("field schedule not found")
-	ErrFieldScheduleIsExist  = errors.New("field schedule is exist")
-)
-
-var FieldScheduleErrors = []error{
-	ErrFieldScheduleNotFound,
-	ErrFieldScheduleIsExist,
-}
+package error
+
+import "errors"
+
+var (
+	ErrFieldScheduleNotFound = errors.New("field schedule not found")
+	ErrFieldScheduleIsExist  = errors.New("field schedule is exist")
+	ErrInvalidDateRange      = errors.New("dateTo must not be before dateFrom")
+)
+
+var FieldScheduleErrors = []error{
+	ErrFieldScheduleNotFound,
+	ErrFieldScheduleIsExist,
+	ErrInvalidDateRange,
+}
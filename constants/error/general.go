@@ -11,6 +11,15 @@ var (
 	ErrInValidUploadFile   = errors.New("invalid upload file")
 	ErrSizeToBig           = errors.New("file size too big")
 	ErrForbidden           = errors.New("forbidden")
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrCursorWithSort      = errors.New("sort is not supported with cursor pagination")
+	ErrUnsupportedImage    = errors.New("unsupported image type")
+	ErrImageTooLarge       = errors.New("image dimensions too large")
+
+	ErrUserServiceUnavailable = errors.New("user service unavailable")
+	ErrRequestExpired         = errors.New("request expired")
+	ErrNonceReused            = errors.New("nonce already used")
+	ErrRequestTooLarge        = errors.New("request body too large")
 )
 
 var GeneralErrors = []error{
@@ -22,4 +31,12 @@ var GeneralErrors = []error{
 	ErrInValidUploadFile,
 	ErrSizeToBig,
 	ErrForbidden,
+	ErrInvalidCursor,
+	ErrCursorWithSort,
+	ErrUnsupportedImage,
+	ErrImageTooLarge,
+	ErrUserServiceUnavailable,
+	ErrRequestExpired,
+	ErrNonceReused,
+	ErrRequestTooLarge,
 }
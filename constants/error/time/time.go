@@ -1,11 +1,11 @@
-package error
-
-import "errors"
-
-var (
-	ErrTimeNotFound = errors.New("time not found")
-)
-
-var TimeErrors = []error{
-	ErrTimeNotFound,
-}
+package error
+
+import "errors"
+
+var (
+	ErrTimeNotFound = errors.New("time not found")
+)
+
+var TimeErrors = []error{
+	ErrTimeNotFound,
+}
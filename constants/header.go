@@ -6,5 +6,13 @@ var (
 	XServiceName  = textproto.CanonicalMIMEHeaderKey("x-service-name")
 	XApiKey       = textproto.CanonicalMIMEHeaderKey("x-api-key")
 	XRequestAt    = textproto.CanonicalMIMEHeaderKey("x-request-at")
+	XNonce        = textproto.CanonicalMIMEHeaderKey("x-nonce")
+	XRequestID    = textproto.CanonicalMIMEHeaderKey("x-request-id")
+	Traceparent   = textproto.CanonicalMIMEHeaderKey("traceparent")
 	Authorization = textproto.CanonicalMIMEHeaderKey("authorization")
+
+	RateLimitLimit     = textproto.CanonicalMIMEHeaderKey("ratelimit-limit")
+	RateLimitRemaining = textproto.CanonicalMIMEHeaderKey("ratelimit-remaining")
+	RateLimitReset     = textproto.CanonicalMIMEHeaderKey("ratelimit-reset")
+	RetryAfter         = textproto.CanonicalMIMEHeaderKey("retry-after")
 )
@@ -0,0 +1,57 @@
+package constants
+
+type Permission string
+
+const (
+	FieldRead        Permission = "field:read"
+	FieldWrite       Permission = "field:write"
+	ScheduleRead     Permission = "schedule:read"
+	ScheduleWrite    Permission = "schedule:write"
+	ScheduleGenerate Permission = "schedule:generate"
+	ScheduleBook     Permission = "schedule:book"
+	TimeRead         Permission = "time:read"
+	TimeWrite        Permission = "time:write"
+)
+
+// rolePermissions grants permissions to roles. Owners get write permissions
+// too, but services only let them change the fields they own and the
+// schedules of those fields.
+var rolePermissions = map[string][]Permission{
+	Admin: {
+		FieldRead,
+		FieldWrite,
+		ScheduleRead,
+		ScheduleWrite,
+		ScheduleGenerate,
+		ScheduleBook,
+		TimeRead,
+		TimeWrite,
+	},
+	Owner: {
+		FieldRead,
+		FieldWrite,
+		ScheduleRead,
+		ScheduleWrite,
+		ScheduleGenerate,
+		TimeRead,
+	},
+	Customer: {
+		FieldRead,
+		ScheduleRead,
+		ScheduleBook,
+	},
+}
+
+func HasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPermission reports whether permission exists; admins hold them all.
+func IsPermission(permission Permission) bool {
+	return HasPermission(Admin, permission)
+}
@@ -3,4 +3,5 @@ package constants
 const (
 	Admin    = "admin"
 	Customer = "customer"
+	Owner    = "owner"
 )
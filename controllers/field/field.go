@@ -1,216 +1,542 @@
-package controllers
-
-import (
-	errValidation "field-service/common/error"
-	"field-service/common/response"
-	"field-service/domain/dto"
-	"field-service/services"
-	"net/http"
-
-	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
-	"github.com/go-playground/validator/v10"
-)
-
-type FieldController struct {
-	service services.IServiceRegistry
-}
-
-type IFieldController interface {
-	GetAllWithPagination(*gin.Context)
-	GetAllWithoutPagination(*gin.Context)
-	GetByUUID(*gin.Context)
-	Create(*gin.Context)
-	Update(*gin.Context)
-	Delete(*gin.Context)
-}
-
-func NewFieldController(service services.IServiceRegistry) IFieldController {
-	return &FieldController{service: service}
-}
-
-func (controller *FieldController) GetAllWithPagination(c *gin.Context) {
-	var params dto.FieldRequestParam
-	err := c.ShouldBindQuery(&params)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	validate := validator.New()
-	err = validate.Struct(params)
-	if err != nil {
-		errMessage := http.StatusText(http.StatusUnprocessableEntity)
-		errorResponse := errValidation.ErrValidationResponse(err)
-
-		response.HttpResponse(response.ParamHTTPResp{
-			Code:    http.StatusBadRequest,
-			Err:     err,
-			Message: &errMessage,
-			Data:    errorResponse,
-			Gin:     c,
-		})
-		return
-	}
-
-	result, err := controller.service.GetField().GetAllWithPagination(c, &params)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusOK,
-		Data: result,
-		Gin:  c,
-	})
-}
-
-func (controller *FieldController) GetAllWithoutPagination(c *gin.Context) {
-	result, err := controller.service.GetField().GetAllWithoutPagination(c)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusOK,
-		Data: result,
-		Gin:  c,
-	})
-}
-
-func (controller *FieldController) GetByUUID(c *gin.Context) {
-	result, err := controller.service.GetField().GetByUUID(c, c.Param("uuid"))
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusOK,
-		Data: result,
-		Gin:  c,
-	})
-}
-
-func (controller *FieldController) Create(c *gin.Context) {
-	var request dto.FieldRequest
-	err := c.ShouldBindWith(&request, binding.FormMultipart)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	validate := validator.New()
-	if err = validate.Struct(request); err != nil {
-		errMessage := http.StatusText(http.StatusUnprocessableEntity)
-		errorResponse := errValidation.ErrValidationResponse(err)
-		response.HttpResponse(response.ParamHTTPResp{
-			Err:     err,
-			Code:    http.StatusUnprocessableEntity,
-			Message: &errMessage,
-			Data:    errorResponse,
-			Gin:     c,
-		})
-		return
-	}
-
-	result, err := controller.service.GetField().Create(c, &request)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusCreated,
-		Data: result,
-		Err:  err,
-		Gin:  c,
-	})
-}
-
-func (controller *FieldController) Update(c *gin.Context) {
-	var request dto.UpdateFieldRequest
-	err := c.ShouldBindWith(&request, binding.FormMultipart)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	validate := validator.New()
-	if err = validate.Struct(request); err != nil {
-		errMessage := http.StatusText(http.StatusUnprocessableEntity)
-		errorResponse := errValidation.ErrValidationResponse(err)
-		response.HttpResponse(response.ParamHTTPResp{
-			Err:     err,
-			Code:    http.StatusUnprocessableEntity,
-			Message: &errMessage,
-			Data:    errorResponse,
-			Gin:     c,
-		})
-		return
-	}
-
-	result, err := controller.service.GetField().Update(c, c.Param("uuid"), &request)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusCreated,
-		Data: result,
-		Err:  err,
-		Gin:  c,
-	})
-}
-
-func (controller *FieldController) Delete(c *gin.Context) {
-	err := controller.service.GetField().Delete(c, c.Param("uuid"))
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusOK,
-		Gin:  c,
-	})
-}
+package controllers
+
+import (
+	errValidation "field-service/common/error"
+	"field-service/common/response"
+	"field-service/common/sorting"
+	"field-service/domain/dto"
+	"field-service/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+type FieldController struct {
+	service services.IServiceRegistry
+}
+
+type IFieldController interface {
+	GetAllWithPagination(*gin.Context)
+	GetAllWithoutPagination(*gin.Context)
+	Search(*gin.Context)
+	GetByUUID(*gin.Context)
+	Create(*gin.Context)
+	Update(*gin.Context)
+	Delete(*gin.Context)
+	AddImages(*gin.Context)
+	RemoveImage(*gin.Context)
+	ReorderImages(*gin.Context)
+	SetCoverImage(*gin.Context)
+	PresignImageUpload(*gin.Context)
+	ConfirmImageUpload(*gin.Context)
+}
+
+func NewFieldController(service services.IServiceRegistry) IFieldController {
+	return &FieldController{service: service}
+}
+
+func (controller *FieldController) GetAllWithPagination(c *gin.Context) {
+	var params dto.FieldRequestParam
+	err := c.ShouldBindQuery(&params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	err = validate.Struct(params)
+	if err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusBadRequest,
+			Err:     err,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	sortSpec := sorting.Spec(params.Sort, params.SortColumn, params.SortOrder)
+	params.Orders, err = sorting.Parse(sortSpec, dto.FieldSortColumns)
+	if err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusBadRequest,
+			Err:     err,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().GetAllWithPagination(c, &params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) GetAllWithoutPagination(c *gin.Context) {
+	result, err := controller.service.GetField().GetAllWithoutPagination(c)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) Search(c *gin.Context) {
+	var params dto.FieldSearchRequestParam
+	err := c.ShouldBindQuery(&params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	err = validate.Struct(params)
+	if err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusBadRequest,
+			Err:     err,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().Search(c, &params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) GetByUUID(c *gin.Context) {
+	result, err := controller.service.GetField().GetByUUID(c, c.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) Create(c *gin.Context) {
+	var request dto.FieldRequest
+	err := c.ShouldBindWith(&request, binding.FormMultipart)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().Create(c, &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusCreated,
+		Data: result,
+		Err:  err,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) Update(c *gin.Context) {
+	var request dto.UpdateFieldRequest
+	err := c.ShouldBindWith(&request, binding.FormMultipart)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().Update(c, c.Param("uuid"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusCreated,
+		Data: result,
+		Err:  err,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) Delete(c *gin.Context) {
+	err := controller.service.GetField().Delete(c, c.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) AddImages(c *gin.Context) {
+	var request dto.AddFieldImagesRequest
+	err := c.ShouldBindWith(&request, binding.FormMultipart)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusBadRequest,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().AddImages(c, c.Param("uuid"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) RemoveImage(c *gin.Context) {
+	var request dto.RemoveFieldImageRequest
+	err := c.ShouldBindQuery(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusBadRequest,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().RemoveImage(c, c.Param("uuid"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) ReorderImages(c *gin.Context) {
+	var request dto.ReorderFieldImagesRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusBadRequest,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().ReorderImages(c, c.Param("uuid"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) SetCoverImage(c *gin.Context) {
+	var request dto.SetCoverFieldImageRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusBadRequest,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().SetCoverImage(c, c.Param("uuid"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) PresignImageUpload(c *gin.Context) {
+	var request dto.PresignFieldImageRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusBadRequest,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().PresignImageUpload(c, c.Param("uuid"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusCreated,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (controller *FieldController) ConfirmImageUpload(c *gin.Context) {
+	var request dto.ConfirmFieldImageRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusBadRequest,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := controller.service.GetField().ConfirmImageUpload(c, c.Param("uuid"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	errValidation "field-service/common/error"
 	"field-service/common/response"
+	"field-service/common/sorting"
 	"field-service/domain/dto"
 	"field-service/services"
 	"net/http"
@@ -57,6 +58,21 @@ func (f *FiledScheduleController) GetAllWithPagination(c *gin.Context) {
 		return
 	}
 
+	sortSpec := sorting.Spec(params.Sort, params.SortColumn, params.SortOrder)
+	params.Orders, err = sorting.Parse(sortSpec, dto.FieldScheduleSortColumns)
+	if err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusBadRequest,
+			Err:     err,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
 	result, err := f.service.GetFieldSchedule().GetAllWithPagination(c, &params)
 	if err != nil {
 		response.HttpResponse(response.ParamHTTPResp{
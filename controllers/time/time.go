@@ -1,107 +1,107 @@
-package controllers
-
-import (
-	errValidation "field-service/common/error"
-	"field-service/common/response"
-	"field-service/domain/dto"
-	"field-service/services"
-	"net/http"
-
-	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-)
-
-type TimeController struct {
-	service services.IServiceRegistry
-}
-
-type ITimeController interface {
-	GetAll(*gin.Context)
-	GetByUUID(*gin.Context)
-	Create(*gin.Context)
-}
-
-func NewTimeController(service services.IServiceRegistry) ITimeController {
-	return &TimeController{service: service}
-}
-
-func (t *TimeController) GetAll(c *gin.Context) {
-	result, err := t.service.GetTime().GetAll(c)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusOK,
-		Data: result,
-		Gin:  c,
-	})
-}
-
-func (t *TimeController) GetByUUID(c *gin.Context) {
-	uuid := c.Param("uuid")
-	result, err := t.service.GetTime().GetByUUID(c, uuid)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusOK,
-		Data: result,
-		Gin:  c,
-	})
-}
-
-func (t *TimeController) Create(c *gin.Context) {
-	var request dto.TimeRequest
-	err := c.ShouldBindJSON(&request)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	validate := validator.New()
-	err = validate.Struct(request)
-	if err != nil {
-		errMessage := http.StatusText(http.StatusUnprocessableEntity)
-		errorResponse := errValidation.ErrValidationResponse(err)
-		response.HttpResponse(response.ParamHTTPResp{
-			Code:    http.StatusBadRequest,
-			Err:     err,
-			Message: &errMessage,
-			Data:    errorResponse,
-			Gin:     c,
-		})
-		return
-	}
-
-	result, err := t.service.GetTime().Create(c, &request)
-	if err != nil {
-		response.HttpResponse(response.ParamHTTPResp{
-			Code: http.StatusBadRequest,
-			Err:  err,
-			Gin:  c,
-		})
-		return
-	}
-
-	response.HttpResponse(response.ParamHTTPResp{
-		Code: http.StatusCreated,
-		Data: result,
-		Gin:  c,
-	})
-}
+package controllers
+
+import (
+	errValidation "field-service/common/error"
+	"field-service/common/response"
+	"field-service/domain/dto"
+	"field-service/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TimeController struct {
+	service services.IServiceRegistry
+}
+
+type ITimeController interface {
+	GetAll(*gin.Context)
+	GetByUUID(*gin.Context)
+	Create(*gin.Context)
+}
+
+func NewTimeController(service services.IServiceRegistry) ITimeController {
+	return &TimeController{service: service}
+}
+
+func (t *TimeController) GetAll(c *gin.Context) {
+	result, err := t.service.GetTime().GetAll(c)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (t *TimeController) GetByUUID(c *gin.Context) {
+	uuid := c.Param("uuid")
+	result, err := t.service.GetTime().GetByUUID(c, uuid)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  c,
+	})
+}
+
+func (t *TimeController) Create(c *gin.Context) {
+	var request dto.TimeRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusBadRequest,
+			Err:     err,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     c,
+		})
+		return
+	}
+
+	result, err := t.service.GetTime().Create(c, &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  c,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusCreated,
+		Data: result,
+		Gin:  c,
+	})
+}
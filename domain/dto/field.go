@@ -1,48 +1,153 @@
-package dto
-
-import (
-	"mime/multipart"
-	"time"
-
-	"github.com/google/uuid"
-)
-
-type FieldRequest struct {
-	Name         string                 `form:"name" validate:"required"`
-	Code         string                 `form:"code" validate:"required"`
-	PricePerHour int                    `form:"pricePerHour" validate:"required"`
-	Images       []multipart.FileHeader `form:"images" validate:"required"`
-}
-
-type UpdateFieldRequest struct {
-	Name         string                 `form:"name" validate:"required"`
-	Code         string                 `form:"code" validate:"required"`
-	PricePerHour int                    `form:"pricePerHour" validate:"required"`
-	Images       []multipart.FileHeader `form:"images"`
-}
-
-type FieldResponse struct {
-	UUID         uuid.UUID  `json:"uuid"`
-	Code         string     `json:"code"`
-	Name         string     `json:"name"`
-	PricePerHour int        `json:"pricePerHour"`
-	Images       []string   `json:"images"`
-	CreatedAt    *time.Time `json:"createdAt"`
-	UpdatedAt    *time.Time `json:"updatedAt"`
-}
-
-type FieldDetailResponse struct {
-	Code         string     `json:"code"`
-	Name         string     `json:"name"`
-	PricePerHour int        `json:"pricePerHour"`
-	Images       []string   `json:"images"`
-	CreatedAt    *time.Time `json:"createdAt"`
-	UpdatedAt    *time.Time `json:"updatedAt"`
-}
-
-type FieldRequestParam struct {
-	Page       int     `form:"page" validate:"required"`
-	Limit      int     `form:"limit" validate:"required"`
-	SortColumn *string `form:"sortColumn"`
-	SortOrder  *string `form:"sortOrder"`
-}
+package dto
+
+import (
+	"field-service/common/sorting"
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FieldRequest struct {
+	Name         string                 `form:"name" validate:"required"`
+	Code         string                 `form:"code" validate:"required"`
+	PricePerHour int                    `form:"pricePerHour" validate:"required"`
+	Description  *string                `form:"description"`
+	Attributes   []string               `form:"attributes"`
+	Images       []multipart.FileHeader `form:"images" validate:"required"`
+	OwnerUUID    *string                `form:"ownerUUID" validate:"omitempty,uuid"`
+}
+
+type UpdateFieldRequest struct {
+	Name         string                 `form:"name" validate:"required"`
+	Code         string                 `form:"code" validate:"required"`
+	PricePerHour int                    `form:"pricePerHour" validate:"required"`
+	Description  *string                `form:"description"`
+	Attributes   []string               `form:"attributes"`
+	Images       []multipart.FileHeader `form:"images"`
+	OwnerUUID    *string                `form:"ownerUUID" validate:"omitempty,uuid"`
+}
+
+type AddFieldImagesRequest struct {
+	Images []multipart.FileHeader `form:"images" validate:"required"`
+}
+
+// RemoveFieldImageRequest picks the image to remove either by its position or
+// by its URL.
+type RemoveFieldImageRequest struct {
+	Index *int   `form:"index" validate:"required_without=URL,omitempty,min=0"`
+	URL   string `form:"url" validate:"required_without=Index"`
+}
+
+// ReorderFieldImagesRequest lists the current image positions in their new
+// order, e.g. [2, 0, 1] moves the third image to the front.
+type ReorderFieldImagesRequest struct {
+	Order []int `json:"order" validate:"required"`
+}
+
+// SetCoverFieldImageRequest moves the image at index to the front; the first
+// image of a field is its cover.
+type SetCoverFieldImageRequest struct {
+	Index *int `json:"index" validate:"required,min=0"`
+}
+
+// PresignFieldImageRequest describes an image the client is about to upload
+// straight to storage; the issued URL only accepts this content type and size.
+type PresignFieldImageRequest struct {
+	ContentType string `json:"contentType" validate:"required,oneof=image/jpeg image/png image/webp"`
+	Size        int64  `json:"size" validate:"required,min=1,max=5242880"`
+}
+
+type PresignFieldImageResponse struct {
+	Key       string            `json:"key"`
+	UploadURL string            `json:"uploadURL"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// ConfirmFieldImageRequest attaches an image uploaded through a presigned URL
+// to the field, by the key returned from the presign endpoint.
+type ConfirmFieldImageRequest struct {
+	Key string `json:"key" validate:"required"`
+}
+
+// DeleteOrphanedImagesRequest configures a storage garbage collection run.
+// Objects younger than GracePeriod are kept, as they may belong to a request
+// or a presigned upload that has not saved its field yet. Fields with images
+// that do not resolve to a key in the configured storage, say after a bucket
+// or base URL change, could still be using objects that look orphaned, so
+// nothing is deleted then unless Force is set.
+type DeleteOrphanedImagesRequest struct {
+	GracePeriod time.Duration
+	DryRun      bool
+	Force       bool
+}
+
+// DeleteOrphanedImagesResult lists the images of fields that resolve to no
+// object key in Unresolved.
+type DeleteOrphanedImagesResult struct {
+	Scanned    int
+	Orphaned   []string
+	Unresolved []string
+	Deleted    int
+}
+
+// FieldResponse lists the original of every image in Images and, at the same
+// index of ImageRenditions, the URLs of its renditions keyed by name.
+type FieldResponse struct {
+	UUID            uuid.UUID           `json:"uuid"`
+	Code            string              `json:"code"`
+	Name            string              `json:"name"`
+	PricePerHour    int                 `json:"pricePerHour"`
+	Description     *string             `json:"description"`
+	Attributes      []string            `json:"attributes"`
+	Images          []string            `json:"images"`
+	ImageRenditions []map[string]string `json:"imageRenditions"`
+	OwnerUUID       *uuid.UUID          `json:"ownerUUID"`
+	CreatedAt       *time.Time          `json:"createdAt"`
+	UpdatedAt       *time.Time          `json:"updatedAt"`
+}
+
+type FieldDetailResponse struct {
+	Code         string     `json:"code"`
+	Name         string     `json:"name"`
+	PricePerHour int        `json:"pricePerHour"`
+	Description  *string    `json:"description"`
+	Attributes   []string   `json:"attributes"`
+	Images       []string   `json:"images"`
+	CreatedAt    *time.Time `json:"createdAt"`
+	UpdatedAt    *time.Time `json:"updatedAt"`
+}
+
+// FieldSortColumns also accepts the snake_case column names the legacy
+// sortColumn parameter took.
+var FieldSortColumns = sorting.Columns{
+	"name":           "fields.name",
+	"code":           "fields.code",
+	"pricePerHour":   "fields.price_per_hour",
+	"price_per_hour": "fields.price_per_hour",
+	"createdAt":      "fields.created_at",
+	"created_at":     "fields.created_at",
+	"updatedAt":      "fields.updated_at",
+	"updated_at":     "fields.updated_at",
+}
+
+// FieldRequestParam switches to keyset pagination when cursor is present;
+// pass an empty cursor for the first page and nextCursor afterwards. Cursor
+// pages are always newest first and reject sort.
+type FieldRequestParam struct {
+	Page       int             `form:"page" validate:"required_without=Cursor"`
+	Limit      int             `form:"limit" validate:"required,min=1,max=100"`
+	Sort       *string         `form:"sort"`
+	SortColumn *string         `form:"sortColumn"`
+	SortOrder  *string         `form:"sortOrder"`
+	Cursor     *string         `form:"cursor"`
+	Orders     []sorting.Order `form:"-"`
+}
+
+type FieldSearchRequestParam struct {
+	Query string `form:"q" validate:"required"`
+	Page  int    `form:"page" validate:"required,min=1"`
+	Limit int    `form:"limit" validate:"required,min=1,max=100"`
+}
@@ -1,57 +1,93 @@
-package dto
-
-import (
-	"field-service/constants"
-	"time"
-
-	"github.com/google/uuid"
-)
-
-type FieldScheduleRequest struct {
-	FieldID string   `json:"fieldID" validate:"required"`
-	Date    string   `json:"date" validate:"required"`
-	TimeIDs []string `json:"timeIDs" validate:"required"`
-}
-
-type GenerateFieldScheduleForOneMonthRequest struct {
-	FieldID string `json:"fieldID" validate:"required"`
-}
-
-type UpdateFieldScheduleRequest struct {
-	Date   string `json:"date" validate:"required"`
-	TimeID string `json:"timeID" validate:"required"`
-}
-
-type UpdateStatusFieldScheduleRequest struct {
-	FieldScheduleIDs []string `json:"fieldScheduleIDs" validate:"required"`
-}
-
-type FieldScheduleResponse struct {
-	UUID         uuid.UUID                         `json:"uuid"`
-	FieldName    string                            `json:"fieldName"`
-	PricePerHour int                               `json:"pricePerHour"`
-	Date         string                            `json:"date"`
-	Status       constants.FieldScheduleStatusName `json:"status"`
-	Time         string                            `json:"time"`
-	CreatedAt    *time.Time                        `json:"createdAt"`
-	UpdatedAt    *time.Time                        `json:"updatedAt"`
-}
-
-type FieldScheduleForBookingResponse struct {
-	UUID         uuid.UUID                         `json:"uuid"`
-	PricePerHour string                            `json:"pricePerHour"`
-	Date         string                            `json:"date"`
-	Status       constants.FieldScheduleStatusName `json:"status"`
-	Time         string                            `json:"time"`
-}
-
-type FieldScheduleRequestParam struct {
-	Page       int     `form:"page" validate:"required"`
-	Limit      int     `form:"limit" validate:"required"`
-	SortColumn *string `form:"sortColumn"`
-	SortOrder  *string `form:"sortOrder"`
-}
-
-type FieldScheduleByFieldIDAndDateRequestParam struct {
-	Date string `form:"date" validate:"required"`
-}
+package dto
+
+import (
+	"field-service/common/sorting"
+	"field-service/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FieldScheduleRequest struct {
+	FieldID string   `json:"fieldID" validate:"required"`
+	Date    string   `json:"date" validate:"required"`
+	TimeIDs []string `json:"timeIDs" validate:"required"`
+}
+
+type GenerateFieldScheduleForOneMonthRequest struct {
+	FieldID string `json:"fieldID" validate:"required"`
+}
+
+type UpdateFieldScheduleRequest struct {
+	Date   string `json:"date" validate:"required"`
+	TimeID string `json:"timeID" validate:"required"`
+}
+
+type UpdateStatusFieldScheduleRequest struct {
+	FieldScheduleIDs []string `json:"fieldScheduleIDs" validate:"required"`
+}
+
+type FieldScheduleResponse struct {
+	UUID         uuid.UUID                         `json:"uuid"`
+	FieldName    string                            `json:"fieldName"`
+	PricePerHour int                               `json:"pricePerHour"`
+	Date         string                            `json:"date"`
+	Status       constants.FieldScheduleStatusName `json:"status"`
+	Time         string                            `json:"time"`
+	CreatedAt    *time.Time                        `json:"createdAt"`
+	UpdatedAt    *time.Time                        `json:"updatedAt"`
+}
+
+type FieldScheduleForBookingResponse struct {
+	UUID         uuid.UUID                         `json:"uuid"`
+	PricePerHour string                            `json:"pricePerHour"`
+	Date         string                            `json:"date"`
+	Status       constants.FieldScheduleStatusName `json:"status"`
+	Time         string                            `json:"time"`
+}
+
+// FieldScheduleSortColumns also accepts snake_case keys, as the legacy
+// sortColumn parameter took column names.
+var FieldScheduleSortColumns = sorting.Columns{
+	"date":           "field_schedules.date",
+	"startTime":      "times.start_time",
+	"start_time":     "times.start_time",
+	"status":         "field_schedules.status",
+	"fieldName":      "fields.name",
+	"field_name":     "fields.name",
+	"pricePerHour":   "fields.price_per_hour",
+	"price_per_hour": "fields.price_per_hour",
+	"createdAt":      "field_schedules.created_at",
+	"created_at":     "field_schedules.created_at",
+	"updatedAt":      "field_schedules.updated_at",
+	"updated_at":     "field_schedules.updated_at",
+}
+
+// FieldScheduleRequestParam switches to keyset pagination when cursor is
+// present; pass an empty cursor for the first page and nextCursor afterwards.
+// Cursor pages are always newest first and reject sort.
+type FieldScheduleRequestParam struct {
+	Page       int             `form:"page" validate:"required_without=Cursor"`
+	Limit      int             `form:"limit" validate:"required,min=1,max=100"`
+	Sort       *string         `form:"sort"`
+	SortColumn *string         `form:"sortColumn"`
+	SortOrder  *string         `form:"sortOrder"`
+	FieldIDs   []string        `form:"fieldID" validate:"omitempty,dive,uuid"`
+	TimeID     *string         `form:"timeID" validate:"omitempty,uuid"`
+	DateFrom   *string         `form:"dateFrom" validate:"omitempty,datetime=2006-01-02"`
+	DateTo     *string         `form:"dateTo" validate:"omitempty,datetime=2006-01-02"`
+	Status     *string         `form:"status" validate:"omitempty,oneof=Available Booked"`
+	Cursor     *string         `form:"cursor"`
+	Orders     []sorting.Order `form:"-"`
+}
+
+type FieldScheduleByFieldIDAndDateRequestParam struct {
+	Date string `form:"date" validate:"required"`
+}
+
+// FieldAvailableSlotCount counts the available schedules of a field.
+type FieldAvailableSlotCount struct {
+	FieldUUID string
+	FieldName string
+	Available int64
+}
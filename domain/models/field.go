@@ -1,22 +1,25 @@
-package models
-
-import (
-	"time"
-
-	"github.com/google/uuid"
-	"github.com/lib/pq"
-	"gorm.io/gorm"
-)
-
-type Field struct {
-	ID            uint           `gorm:"primaryKey;autoIncrement"`
-	UUID          uuid.UUID      `gorm:"type:uuid;not null"`
-	Code          string         `gorm:"type:varchar(15);not null"`
-	Name          string         `gorm:"type:varchar(100);not null"`
-	PricePerHour  int            `gorm:"type:int;not null"`
-	Images        pq.StringArray `gorm:"type:text[];not null"`
-	CreatedAt     *time.Time
-	UpdatedAt     *time.Time
-	DeletedAt     *gorm.DeletedAt
-	FieldSchedule []FieldSchedule `gorm:"foreignKey:field_id;references:id;constraint:OnUpdate:CASCADE,onDelete:CASCADE"`
-}
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type Field struct {
+	ID            uint           `gorm:"primaryKey;autoIncrement;index:idx_fields_created_at_id,priority:2"`
+	UUID          uuid.UUID      `gorm:"type:uuid;not null"`
+	Code          string         `gorm:"type:varchar(15);not null"`
+	Name          string         `gorm:"type:varchar(100);not null"`
+	PricePerHour  int            `gorm:"type:int;not null"`
+	Description   *string        `gorm:"type:text"`
+	Attributes    pq.StringArray `gorm:"type:text[]"`
+	Images        pq.StringArray `gorm:"type:text[];not null"`
+	OwnerUUID     *uuid.UUID     `gorm:"type:uuid;index"`
+	CreatedAt     *time.Time     `gorm:"not null;index:idx_fields_created_at_id,priority:1"`
+	UpdatedAt     *time.Time
+	DeletedAt     *gorm.DeletedAt
+	FieldSchedule []FieldSchedule `gorm:"foreignKey:field_id;references:id;constraint:OnUpdate:CASCADE,onDelete:CASCADE"`
+}
@@ -1,22 +1,22 @@
-package models
-
-import (
-	"field-service/constants"
-	"time"
-
-	"github.com/google/uuid"
-)
-
-type FieldSchedule struct {
-	ID        uint                          `gorm:"primaryKey;autoIncrement"`
-	UUID      uuid.UUID                     `gorm:"type:uuid;not null"`
-	FieldID   uint                          `gorm:"type:int;not null"`
-	TimeID    uint                          `gorm:"type:int;not null"`
-	Date      time.Time                     `gorm:"type:date;not null"`
-	Status    constants.FieldScheduleStatus `gorm:"type:int;not null"`
-	CreatedAt *time.Time
-	UpdatedAt *time.Time
-	DeletedAt *time.Time
-	Field     Field `gorm:"foreignKey:field_id;references:id;constraint:OnUpdate:CASCADE,onDelete:CASCADE"`
-	Time      Time  `gorm:"foreignKey:time_id;references:id;constraint:OnUpdate:CASCADE,onDelete:CASCADE"`
-}
+package models
+
+import (
+	"field-service/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FieldSchedule struct {
+	ID        uint                          `gorm:"primaryKey;autoIncrement;index:idx_field_schedules_created_at_id,priority:2"`
+	UUID      uuid.UUID                     `gorm:"type:uuid;not null"`
+	FieldID   uint                          `gorm:"type:int;not null"`
+	TimeID    uint                          `gorm:"type:int;not null"`
+	Date      time.Time                     `gorm:"type:date;not null"`
+	Status    constants.FieldScheduleStatus `gorm:"type:int;not null"`
+	CreatedAt *time.Time                    `gorm:"not null;index:idx_field_schedules_created_at_id,priority:1"`
+	UpdatedAt *time.Time
+	DeletedAt *time.Time
+	Field     Field `gorm:"foreignKey:field_id;references:id;constraint:OnUpdate:CASCADE,onDelete:CASCADE"`
+	Time      Time  `gorm:"foreignKey:time_id;references:id;constraint:OnUpdate:CASCADE,onDelete:CASCADE"`
+}
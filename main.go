@@ -1,7 +1,7 @@
-package main
-
-import "field-service/cmd"
-
-func main() {
-	cmd.Run()
-}
+package main
+
+import "field-service/cmd"
+
+func main() {
+	cmd.Run()
+}
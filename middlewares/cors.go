@@ -0,0 +1,141 @@
+package middlewares
+
+import (
+	"errors"
+	"field-service/config"
+	"field-service/constants"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	defaultCORSMethods = []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+	}
+	defaultCORSHeaders = []string{
+		"Content-Type",
+		constants.Authorization,
+		constants.XServiceName,
+		constants.XRequestAt,
+		constants.XApiKey,
+		constants.XNonce,
+		constants.XRequestID,
+	}
+	defaultCORSExposedHeaders = []string{
+		constants.XRequestID,
+		constants.RateLimitLimit,
+		constants.RateLimitRemaining,
+		constants.RateLimitReset,
+		constants.RetryAfter,
+	}
+)
+
+// defaultCORSOrigins applies when cors.allowedOrigins is not configured.
+var defaultCORSOrigins = []string{"*"}
+
+func orDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
+
+func matchOrigin(pattern, origin string) bool {
+	if pattern == "*" {
+		return true
+	}
+
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return strings.EqualFold(pattern, origin)
+	}
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+		strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix))
+}
+
+// CheckCORS fails on credentials allowed for every origin, which would let
+// any site make credentialed requests on behalf of its visitors. That includes
+// the default origins when cors.allowedOrigins is left empty.
+func CheckCORS() error {
+	corsConfig := config.Config.CORS
+	if !corsConfig.AllowCredentials {
+		return nil
+	}
+
+	for _, pattern := range orDefault(corsConfig.AllowedOrigins, defaultCORSOrigins) {
+		if pattern == "*" {
+			return errors.New(`cors.allowCredentials cannot be combined with the "*" origin, list the allowed origins`)
+		}
+	}
+	return nil
+}
+
+// CORS answers preflights for every route, registered or not, and adds the
+// CORS headers to the responses of allowed origins. It has to run before any
+// other middleware so rejected or failing requests still carry them.
+func CORS() gin.HandlerFunc {
+	corsConfig := config.Config.CORS
+	origins := orDefault(corsConfig.AllowedOrigins, defaultCORSOrigins)
+	methods := strings.Join(orDefault(corsConfig.AllowedMethods, defaultCORSMethods), ", ")
+	headers := strings.Join(orDefault(corsConfig.AllowedHeaders, defaultCORSHeaders), ", ")
+	exposed := strings.Join(orDefault(corsConfig.ExposedHeaders, defaultCORSExposedHeaders), ", ")
+	maxAge := strconv.Itoa(corsConfig.MaxAgeSecond)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		allowed := false
+		for _, pattern := range origins {
+			if matchOrigin(pattern, origin) {
+				allowed = true
+				break
+			}
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// CheckCORS keeps credentials away from a wildcard origin.
+		if len(origins) == 1 && origins[0] == "*" {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if corsConfig.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			c.Header("Access-Control-Expose-Headers", exposed)
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", methods)
+		c.Header("Access-Control-Allow-Headers", headers)
+		if corsConfig.MaxAgeSecond > 0 {
+			c.Header("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
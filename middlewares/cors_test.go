@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"field-service/config"
+	"testing"
+)
+
+func TestCheckCORS(t *testing.T) {
+	previous := config.Config
+	t.Cleanup(func() { config.Config = previous })
+
+	tests := []struct {
+		name             string
+		allowedOrigins   []string
+		allowCredentials bool
+		wantErr          bool
+	}{
+		{name: "default origins"},
+		{name: "wildcard origin", allowedOrigins: []string{"*"}},
+		{name: "credentials with listed origins", allowedOrigins: []string{"https://*.example.com"}, allowCredentials: true},
+		{name: "credentials with default origins", allowCredentials: true, wantErr: true},
+		{name: "credentials with wildcard origin", allowedOrigins: []string{"https://example.com", "*"},
+			allowCredentials: true, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config.Config.CORS = config.CORS{
+				AllowedOrigins:   test.allowedOrigins,
+				AllowCredentials: test.allowCredentials,
+			}
+
+			if err := CheckCORS(); (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	clients "field-service/clients/user"
+	"field-service/common/logger"
+	"field-service/common/trace"
+	"field-service/constants"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Logger logs one structured entry per request. The caller's x-request-id and
+// traceparent are continued when present, otherwise a new trace is started;
+// it is put into the request context for later log lines and outbound calls,
+// and the request ID is echoed in the response. Headers are only logged at
+// debug level, with sensitive values redacted.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestTrace := trace.New(c.GetHeader(constants.XRequestID), c.GetHeader(constants.Traceparent))
+		requestID := requestTrace.RequestID
+		c.Request = c.Request.WithContext(trace.WithContext(c.Request.Context(), requestTrace))
+		c.Set(constants.RequestID, requestID)
+		c.Header(constants.XRequestID, requestID)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := logrus.Fields{
+			"request_id":   requestID,
+			"trace_id":     requestTrace.TraceID,
+			"span_id":      requestTrace.SpanID,
+			"method":       c.Request.Method,
+			"route":        c.FullPath(),
+			"path":         c.Request.URL.Path,
+			"status":       status,
+			"latency_ms":   time.Since(start).Milliseconds(),
+			"client_ip":    c.ClientIP(),
+			"service_name": c.GetHeader(constants.XServiceName),
+		}
+		if user, ok := c.Get(constants.User); ok {
+			fields["user_uuid"] = user.(*clients.UserData).UUID.String()
+		}
+		if logrus.IsLevelEnabled(logrus.DebugLevel) {
+			fields["headers"] = logger.RedactHeaders(c.Request.Header)
+		}
+		if len(c.Errors) > 0 {
+			fields["errors"] = c.Errors.String()
+		}
+
+		entry := logrus.WithFields(fields)
+		switch {
+		case status >= http.StatusInternalServerError:
+			entry.Error("request completed")
+		case status >= http.StatusBadRequest:
+			entry.Warn("request completed")
+		default:
+			entry.Info("request completed")
+		}
+	}
+}
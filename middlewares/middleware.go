@@ -1,152 +1,263 @@
-package middlewares
-
-import (
-	"context"
-	"crypto/sha256"
-	"encoding/hex"
-	"field-service/clients"
-	"field-service/common/response"
-	"field-service/config"
-	"field-service/constants"
-	errConstant "field-service/constants/error"
-	"fmt"
-	"net/http"
-	"strings"
-
-	"github.com/didip/tollbooth"
-	"github.com/didip/tollbooth/limiter"
-	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
-)
-
-func HandlePanic() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		defer func() {
-			if r := recover(); r != nil {
-				logrus.Errorf("Recovered from panic: %v", r)
-				c.JSON(http.StatusInternalServerError, response.Response{
-					Status:  constants.Error,
-					Message: errConstant.ErrInternalServerError.Error(),
-				})
-				c.Abort()
-			}
-		}()
-		c.Next()
-	}
-}
-
-func RateLimiter(lmt *limiter.Limiter) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		err := tollbooth.LimitByRequest(lmt, c.Writer, c.Request)
-		if err != nil {
-			c.JSON(http.StatusTooManyRequests, response.Response{
-				Status:  constants.Error,
-				Message: errConstant.ErrTooManyRequests.Error(),
-			})
-			c.Abort()
-		}
-		c.Next()
-	}
-}
-
-func extractBearerToken(token string) string {
-	arrayToken := strings.Split(token, " ")
-	if len(arrayToken) == 2 {
-		return arrayToken[1]
-	}
-	return ""
-}
-
-func responseUnauthorized(c *gin.Context, message string) {
-	c.JSON(http.StatusUnauthorized, response.Response{
-		Status:  constants.Error,
-		Message: message,
-	})
-	c.Abort()
-}
-
-func validateAPIKey(c *gin.Context) error {
-	apiKey := c.GetHeader(constants.XApiKey)
-	requestAt := c.GetHeader(constants.XRequestAt)
-	serviceName := c.GetHeader(constants.XServiceName)
-	signatureKey := config.Config.SignatureKey
-
-	validateKey := fmt.Sprintf("%s:%s:%s", serviceName, signatureKey, requestAt)
-	hash := sha256.New()
-	hash.Write([]byte(validateKey))
-	resultHash := hex.EncodeToString(hash.Sum(nil))
-
-	// Tambahkan log untuk debugging
-	logrus.Infof("Received API key: %s", apiKey)
-	logrus.Infof("Expected hash: %s", resultHash)
-	logrus.Infof("Service name: %s, Request at: %s", serviceName, requestAt)
-
-	if apiKey != resultHash {
-		return errConstant.ErrUnauthorized
-	}
-	return nil
-}
-
-func contains(roles []string, role string) bool {
-	for _, r := range roles {
-		if r == role {
-			return true
-		}
-	}
-	return false
-}
-
-func CheckRole(roles []string, client clients.IClientRegistry) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		logrus.Info("Checking user role")
-
-		user, err := client.GetUser().GetUserByToken(c.Request.Context())
-		if err != nil {
-			logrus.Errorf("Failed to get user by token: %v", err)
-			responseUnauthorized(c, errConstant.ErrUnauthorized.Error())
-			return
-		}
-
-		logrus.Infof("User role: %s, Required roles: %v", user.Role, roles)
-
-		if !contains(roles, user.Role) {
-			logrus.Errorf("User role %s not in required roles %v", user.Role, roles)
-			responseUnauthorized(c, errConstant.ErrUnauthorized.Error())
-			return
-		}
-		c.Next()
-	}
-}
-
-func Authenticate() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var err error
-		token := c.GetHeader(constants.Authorization)
-		if token == "" {
-			responseUnauthorized(c, errConstant.ErrUnauthorized.Error())
-			return
-		}
-
-		err = validateAPIKey(c)
-		if err != nil {
-			responseUnauthorized(c, err.Error())
-			return
-		}
-
-		tokenString := extractBearerToken(token)
-		tokenUser := c.Request.WithContext(context.WithValue(c.Request.Context(), constants.Token, tokenString))
-		c.Request = tokenUser
-		c.Next()
-	}
-}
-
-func AuthenticateWithoutToken() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		err := validateAPIKey(c)
-		if err != nil {
-			responseUnauthorized(c, err.Error())
-			return
-		}
-		c.Next()
-	}
-}
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"field-service/clients"
+	userClient "field-service/clients/user"
+	"field-service/common/auth"
+	"field-service/common/ratelimit"
+	"field-service/common/response"
+	"field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultMaxSkew applies when apiKey.maxSkewSecond is not configured.
+	defaultMaxSkew = 5 * time.Minute
+
+	// defaultMaxBodyBytes applies when apiKey.maxBodyBytes is not configured.
+	// It leaves room for a multipart upload of several images.
+	defaultMaxBodyBytes = 32 << 20
+)
+
+func HandlePanic() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				logrus.WithContext(c.Request.Context()).Errorf("Recovered from panic: %v", r)
+				responseError(c, http.StatusInternalServerError, errConstant.ErrInternalServerError.Error())
+			}
+		}()
+		c.Next()
+	}
+}
+
+func extractBearerToken(token string) string {
+	arrayToken := strings.Split(token, " ")
+	if len(arrayToken) == 2 {
+		return arrayToken[1]
+	}
+	return ""
+}
+
+func responseError(c *gin.Context, code int, message string) {
+	c.JSON(code, response.Response{
+		Status:    constants.Error,
+		Message:   message,
+		RequestID: c.GetString(constants.RequestID),
+	})
+	c.Abort()
+}
+
+func responseUnauthorized(c *gin.Context, message string) {
+	responseError(c, http.StatusUnauthorized, message)
+}
+
+func responseForbidden(c *gin.Context, message string) {
+	responseError(c, http.StatusForbidden, message)
+}
+
+func responseServiceUnavailable(c *gin.Context, message string) {
+	responseError(c, http.StatusServiceUnavailable, message)
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// bodyHash hashes the request body and puts it back for the handlers. Bodies
+// over maxBytes are rejected without reading them to the end.
+func bodyHash(c *gin.Context, maxBytes int64) (string, error) {
+	if c.Request.Body == nil {
+		return sha256Hex(nil), nil
+	}
+
+	if c.Request.ContentLength > maxBytes {
+		return "", errConstant.ErrRequestTooLarge
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return "", errConstant.ErrRequestTooLarge
+		}
+		return "", err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return sha256Hex(body), nil
+}
+
+// validateAPIKey checks x-api-key against
+// sha256(service:key:requestAt:nonce:METHOD:requestURI:sha256(body)), or
+// against the legacy sha256(service:key:requestAt) when allowed, for every key
+// the caller may sign with. A valid signature is still rejected outside the
+// clock-skew window or when its nonce was already used according to nonces.
+func validateAPIKey(c *gin.Context, nonces ratelimit.Store) error {
+	apiKey := c.GetHeader(constants.XApiKey)
+	requestAt := c.GetHeader(constants.XRequestAt)
+	serviceName := c.GetHeader(constants.XServiceName)
+	nonce := c.GetHeader(constants.XNonce)
+	apiKeyConfig := config.Config.APIKey
+
+	maxSkew := time.Duration(apiKeyConfig.MaxSkewSecond) * time.Second
+	if maxSkew <= 0 {
+		maxSkew = defaultMaxSkew
+	}
+
+	maxBodyBytes := apiKeyConfig.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+
+	requestAtUnix, err := strconv.ParseInt(requestAt, 10, 64)
+	if err != nil {
+		return errConstant.ErrUnauthorized
+	}
+
+	skew := time.Since(time.Unix(requestAtUnix, 0))
+	if skew > maxSkew || skew < -maxSkew {
+		return errConstant.ErrRequestExpired
+	}
+
+	if nonce == "" && apiKeyConfig.RequireNonce {
+		return errConstant.ErrUnauthorized
+	}
+
+	hash, err := bodyHash(c, maxBodyBytes)
+	if err != nil {
+		if errors.Is(err, errConstant.ErrRequestTooLarge) {
+			return err
+		}
+		return errConstant.ErrUnauthorized
+	}
+
+	valid := false
+	for _, signatureKey := range signatureKeys(serviceName) {
+		validateKey := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s",
+			serviceName,
+			signatureKey,
+			requestAt,
+			nonce,
+			c.Request.Method,
+			c.Request.URL.RequestURI(),
+			hash,
+		)
+		resultHash := sha256Hex([]byte(validateKey))
+
+		valid = subtle.ConstantTimeCompare([]byte(apiKey), []byte(resultHash)) == 1
+		if !valid && apiKeyConfig.AllowLegacy {
+			legacyHash := sha256Hex([]byte(fmt.Sprintf("%s:%s:%s", serviceName, signatureKey, requestAt)))
+			valid = subtle.ConstantTimeCompare([]byte(apiKey), []byte(legacyHash)) == 1
+		}
+		if valid {
+			break
+		}
+	}
+	if !valid {
+		logrus.WithContext(c.Request.Context()).Warnf("invalid api key from service %q", serviceName)
+		return errConstant.ErrUnauthorized
+	}
+
+	if nonce == "" {
+		return nil
+	}
+
+	// Nonces only live as long as the skew window lets their request through.
+	// Unlike a rate limit, a nonce that cannot be checked is not let through.
+	key := fmt.Sprintf("nonce:%s:%s", serviceName, nonce)
+	result, err := nonces.Take(c.Request.Context(), key, 1, 2*maxSkew)
+	if err != nil {
+		logrus.WithContext(c.Request.Context()).Errorf("nonce store: %v", err)
+		return errConstant.ErrUnauthorized
+	}
+	if !result.Allowed {
+		return errConstant.ErrNonceReused
+	}
+	return nil
+}
+
+func contains(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticatedUser looks up the user of the bearer token and hands it to the
+// handlers and, through the request context, to the services. It writes the
+// error response itself when the lookup fails.
+func authenticatedUser(c *gin.Context, client clients.IClientRegistry) (*userClient.UserData, bool) {
+	user, err := client.GetUser().GetUserByToken(c.Request.Context())
+	if err != nil {
+		logrus.WithContext(c.Request.Context()).Errorf("Failed to get user by token: %v", err)
+		if errors.Is(err, errConstant.ErrUserServiceUnavailable) {
+			responseServiceUnavailable(c, errConstant.ErrUserServiceUnavailable.Error())
+			return nil, false
+		}
+		responseUnauthorized(c, errConstant.ErrUnauthorized.Error())
+		return nil, false
+	}
+
+	c.Set(constants.User, user)
+	c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), user))
+	return user, true
+}
+
+// authenticateService checks the API key and scopes of the calling service.
+// It writes the error response itself when they fail.
+func authenticateService(c *gin.Context, nonces ratelimit.Store) bool {
+	err := validateAPIKey(c, nonces)
+	if err != nil {
+		if errors.Is(err, errConstant.ErrRequestTooLarge) {
+			responseError(c, http.StatusRequestEntityTooLarge, err.Error())
+			return false
+		}
+		responseUnauthorized(c, err.Error())
+		return false
+	}
+
+	err = checkServiceScope(c)
+	if err != nil {
+		responseForbidden(c, err.Error())
+		return false
+	}
+	return true
+}
+
+// authenticateToken also requires a bearer token, which it puts into the
+// request context for the user client.
+func authenticateToken(c *gin.Context, nonces ratelimit.Store) bool {
+	token := c.GetHeader(constants.Authorization)
+	if token == "" {
+		responseUnauthorized(c, errConstant.ErrUnauthorized.Error())
+		return false
+	}
+
+	if !authenticateService(c, nonces) {
+		return false
+	}
+
+	tokenString := extractBearerToken(token)
+	tokenUser := c.Request.WithContext(context.WithValue(c.Request.Context(), constants.Token, tokenString))
+	c.Request = tokenUser
+	return true
+}
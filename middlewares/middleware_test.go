@@ -0,0 +1,190 @@
+package middlewares
+
+import (
+	"errors"
+	"field-service/common/ratelimit"
+	"field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type signedRequest struct {
+	service   string
+	key       string
+	requestAt time.Time
+	nonce     string
+	method    string
+	uri       string
+	body      string
+	legacy    bool
+}
+
+func (r signedRequest) build() *http.Request {
+	requestAt := strconv.FormatInt(r.requestAt.Unix(), 10)
+	apiKey := sha256Hex([]byte(fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s",
+		r.service, r.key, requestAt, r.nonce, r.method, r.uri, sha256Hex([]byte(r.body)))))
+	if r.legacy {
+		apiKey = sha256Hex([]byte(fmt.Sprintf("%s:%s:%s", r.service, r.key, requestAt)))
+	}
+
+	request := httptest.NewRequest(r.method, r.uri, strings.NewReader(r.body))
+	request.Header.Set(constants.XApiKey, apiKey)
+	request.Header.Set(constants.XRequestAt, requestAt)
+	request.Header.Set(constants.XServiceName, r.service)
+	if r.nonce != "" {
+		request.Header.Set(constants.XNonce, r.nonce)
+	}
+	return request
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	previous := config.Config
+	t.Cleanup(func() { config.Config = previous })
+
+	valid := signedRequest{
+		service:   "order-service",
+		key:       "order-key",
+		requestAt: time.Now(),
+		method:    http.MethodPatch,
+		uri:       "/api/v1/field/schedule/status?x=1",
+		body:      `{"fieldScheduleIDs":["a"]}`,
+	}
+	tamper := func(change func(*signedRequest)) signedRequest {
+		request := valid
+		change(&request)
+		return request
+	}
+
+	tests := []struct {
+		name        string
+		request     signedRequest
+		allowLegacy bool
+		edit        func(*http.Request)
+		want        error
+	}{
+		{name: "signed", request: valid},
+		{name: "rotated key", request: tamper(func(r *signedRequest) { r.key = "old-key" })},
+		{name: "shared key", request: tamper(func(r *signedRequest) { r.service, r.key = "gateway", "shared" })},
+		{
+			name:    "unknown key",
+			request: tamper(func(r *signedRequest) { r.key = "other" }),
+			want:    errConstant.ErrUnauthorized,
+		},
+		{
+			name:    "key of another service",
+			request: tamper(func(r *signedRequest) { r.key = "shared" }),
+			want:    errConstant.ErrUnauthorized,
+		},
+		{
+			name:    "tampered body",
+			request: valid,
+			edit: func(request *http.Request) {
+				request.Body = http.NoBody
+			},
+			want: errConstant.ErrUnauthorized,
+		},
+		{
+			name:    "tampered path",
+			request: valid,
+			edit: func(request *http.Request) {
+				request.URL.RawQuery = "x=2"
+			},
+			want: errConstant.ErrUnauthorized,
+		},
+		{
+			name:    "expired",
+			request: tamper(func(r *signedRequest) { r.requestAt = time.Now().Add(-10 * time.Minute) }),
+			want:    errConstant.ErrRequestExpired,
+		},
+		{
+			name:    "from the future",
+			request: tamper(func(r *signedRequest) { r.requestAt = time.Now().Add(10 * time.Minute) }),
+			want:    errConstant.ErrRequestExpired,
+		},
+		{
+			name:        "legacy allowed",
+			request:     tamper(func(r *signedRequest) { r.legacy = true }),
+			allowLegacy: true,
+		},
+		{
+			name:    "legacy rejected",
+			request: tamper(func(r *signedRequest) { r.legacy = true }),
+			want:    errConstant.ErrUnauthorized,
+		},
+		{
+			name:    "body too large",
+			request: tamper(func(r *signedRequest) { r.body = strings.Repeat("a", 65) }),
+			want:    errConstant.ErrRequestTooLarge,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config.Config.SignatureKey = "shared"
+			config.Config.Services = []config.Service{
+				{Name: "order-service", SignatureKeys: []string{"order-key", "old-key"}},
+			}
+			config.Config.APIKey = config.APIKey{AllowLegacy: test.allowLegacy, MaxBodyBytes: 64}
+
+			request := test.request.build()
+			if test.edit != nil {
+				test.edit(request)
+			}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = request
+
+			err := validateAPIKey(c, ratelimit.NewMemoryStore())
+			if !errors.Is(err, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateAPIKeyNonce(t *testing.T) {
+	previous := config.Config
+	t.Cleanup(func() { config.Config = previous })
+	config.Config.SignatureKey = "shared"
+	config.Config.Services = nil
+	config.Config.APIKey = config.APIKey{RequireNonce: true}
+
+	nonces := ratelimit.NewMemoryStore()
+	request := signedRequest{
+		service:   "gateway",
+		key:       "shared",
+		requestAt: time.Now(),
+		method:    http.MethodGet,
+		uri:       "/api/v1/field",
+	}
+
+	tests := []struct {
+		name  string
+		nonce string
+		want  error
+	}{
+		{name: "missing nonce", want: errConstant.ErrUnauthorized},
+		{name: "new nonce", nonce: "n1"},
+		{name: "reused nonce", nonce: "n1", want: errConstant.ErrNonceReused},
+		{name: "another nonce", nonce: "n2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request.nonce = test.nonce
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = request.build()
+
+			err := validateAPIKey(c, nonces)
+			if !errors.Is(err, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
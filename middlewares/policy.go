@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"field-service/clients"
+	"field-service/common/ratelimit"
+	"field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// FindPolicy returns the first policy matching method and route, the pattern
+// the route was registered with.
+func FindPolicy(policies []config.RoutePolicy, method, route string) (*config.RoutePolicy, bool) {
+	for i, policy := range policies {
+		if matchScope(policy.Route, method, route) {
+			return &policies[i], true
+		}
+	}
+	return nil, false
+}
+
+// Authorize authenticates and authorizes every call by the policy of its
+// route. Routes without a policy are closed. Nonces of signed calls are used up
+// in the nonces store.
+func Authorize(
+	policies []config.RoutePolicy,
+	client clients.IClientRegistry,
+	nonces ratelimit.Store,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, ok := FindPolicy(policies, c.Request.Method, c.FullPath())
+		if !ok {
+			logrus.WithContext(c.Request.Context()).Errorf("no policy for %s %s", c.Request.Method, c.FullPath())
+			responseForbidden(c, errConstant.ErrForbidden.Error())
+			return
+		}
+
+		if !policy.Token {
+			ok = authenticateService(c, nonces)
+		} else {
+			ok = authenticateToken(c, nonces)
+		}
+		if !ok {
+			return
+		}
+
+		serviceName := c.GetHeader(constants.XServiceName)
+		if len(policy.Services) > 0 && !contains(policy.Services, serviceName) {
+			logrus.WithContext(c.Request.Context()).Errorf("Service %q not allowed on %s", serviceName, policy.Route)
+			responseForbidden(c, errConstant.ErrForbidden.Error())
+			return
+		}
+
+		if !policy.Token {
+			c.Next()
+			return
+		}
+
+		user, ok := authenticatedUser(c, client)
+		if !ok {
+			return
+		}
+
+		if len(policy.Roles) > 0 && !contains(policy.Roles, user.Role) {
+			logrus.WithContext(c.Request.Context()).Errorf("User role %s not in required roles %v", user.Role, policy.Roles)
+			responseForbidden(c, errConstant.ErrForbidden.Error())
+			return
+		}
+
+		for _, permission := range policy.Permissions {
+			if !constants.HasPermission(user.Role, constants.Permission(permission)) {
+				logrus.WithContext(c.Request.Context()).Errorf("User role %s lacks permission %s", user.Role, permission)
+				responseForbidden(c, errConstant.ErrForbidden.Error())
+				return
+			}
+		}
+		c.Next()
+	}
+}
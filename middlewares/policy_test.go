@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"field-service/config"
+	"testing"
+)
+
+func TestFindPolicy(t *testing.T) {
+	policies := []config.RoutePolicy{
+		{Route: "GET /api/v1/field/schedule/lists/:uuid"},
+		{Route: "GET /api/v1/field/schedule/*", Token: true},
+		{Route: "* /api/v1/field/schedule*", Token: true, Permissions: []string{"schedule:write"}},
+	}
+
+	tests := []struct {
+		method string
+		route  string
+		want   string
+	}{
+		{method: "GET", route: "/api/v1/field/schedule/lists/:uuid", want: policies[0].Route},
+		{method: "GET", route: "/api/v1/field/schedule/:uuid", want: policies[1].Route},
+		{method: "POST", route: "/api/v1/field/schedule", want: policies[2].Route},
+		{method: "GET", route: "/api/v1/field/schedule", want: policies[2].Route},
+		{method: "GET", route: "/api/v1/time", want: ""},
+	}
+	for _, test := range tests {
+		policy, ok := FindPolicy(policies, test.method, test.route)
+		got := ""
+		if ok {
+			got = policy.Route
+		}
+		if got != test.want {
+			t.Errorf("FindPolicy(%q, %q) = %q, want %q", test.method, test.route, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package middlewares
+
+import (
+	clients "field-service/clients/user"
+	"field-service/common/metrics"
+	"field-service/common/ratelimit"
+	"field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	rateLimitByUser    = "user"
+	rateLimitByService = "service"
+	rateLimitByIP      = "ip"
+)
+
+// rateLimitRules falls back to the global per-IP limit of
+// rateLimiterMaxRequest and rateLimiterTimeSecond when no rules are set.
+func rateLimitRules() []config.RateLimitRule {
+	if len(config.Config.RateLimit.Rules) > 0 {
+		return config.Config.RateLimit.Rules
+	}
+
+	if config.Config.RateLimiterMaxRequest <= 0 {
+		return nil
+	}
+
+	return []config.RateLimitRule{{
+		Name:         "default",
+		Route:        "* /*",
+		Key:          rateLimitByIP,
+		MaxRequest:   int(config.Config.RateLimiterMaxRequest),
+		WindowSecond: config.Config.RateLimiterTimeSecond,
+	}}
+}
+
+func rateLimitIdentity(c *gin.Context, key string) string {
+	if key == rateLimitByUser {
+		if value, ok := c.Get(constants.User); ok {
+			if user, ok := value.(*clients.UserData); ok && user != nil {
+				return fmt.Sprintf("user:%s", user.UUID)
+			}
+		}
+	}
+
+	if key == rateLimitByUser || key == rateLimitByService {
+		if serviceName := c.GetHeader(constants.XServiceName); serviceName != "" {
+			return fmt.Sprintf("service:%s", serviceName)
+		}
+	}
+	return fmt.Sprintf("ip:%s", c.ClientIP())
+}
+
+func setRateLimitHeaders(c *gin.Context, result *ratelimit.Result) {
+	reset := strconv.Itoa(int(math.Ceil(result.ResetIn.Seconds())))
+	c.Header(constants.RateLimitLimit, strconv.Itoa(result.Limit))
+	c.Header(constants.RateLimitRemaining, strconv.Itoa(result.Remaining))
+	c.Header(constants.RateLimitReset, reset)
+	if !result.Allowed {
+		c.Header(constants.RetryAfter, reset)
+	}
+}
+
+// CheckRateLimitRules fails on rules that could not be applied as written.
+func CheckRateLimitRules() error {
+	for _, rule := range rateLimitRules() {
+		if rule.Name == "" {
+			return fmt.Errorf("rate limit rule %q: name is required", rule.Route)
+		}
+
+		if !strings.Contains(strings.TrimSpace(rule.Route), " ") {
+			return fmt.Errorf("rate limit rule %q: route must be \"METHOD /route\"", rule.Name)
+		}
+
+		switch rule.Key {
+		case rateLimitByUser, rateLimitByService, rateLimitByIP:
+		default:
+			return fmt.Errorf("rate limit rule %q: unknown key %q", rule.Name, rule.Key)
+		}
+	}
+	return nil
+}
+
+// filterRules returns the rules keyed by one of keys.
+func filterRules(rules []config.RateLimitRule, keys ...string) []config.RateLimitRule {
+	filtered := make([]config.RateLimitRule, 0, len(rules))
+	for _, rule := range rules {
+		if slices.Contains(keys, rule.Key) {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
+// IPRateLimiter applies the rules keyed by IP. It runs on the router before
+// authentication, so floods are turned away before they cost a signature
+// check or a user lookup. c.ClientIP only honors X-Forwarded-For from the
+// configured trusted proxies.
+func IPRateLimiter(store ratelimit.Store) gin.HandlerFunc {
+	return rateLimiter(store, filterRules(rateLimitRules(), rateLimitByIP))
+}
+
+// RateLimiter applies the rules keyed by user or service. It runs after
+// authorization, once the user and the service are known.
+func RateLimiter(store ratelimit.Store) gin.HandlerFunc {
+	return rateLimiter(store, filterRules(rateLimitRules(), rateLimitByUser, rateLimitByService))
+}
+
+// rateLimiter counts the call against every rule matching its route and
+// rejects it once one of them is exhausted, reporting the tightest rule seen
+// so far in the RateLimit headers. When the store fails, calls are let
+// through.
+func rateLimiter(store ratelimit.Store, rules []config.RateLimitRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(rules) == 0 {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		var tightest *ratelimit.Result
+		if value, ok := c.Get(constants.RateLimit); ok {
+			tightest, _ = value.(*ratelimit.Result)
+		}
+		for _, rule := range rules {
+			if rule.MaxRequest <= 0 || !matchScope(rule.Route, c.Request.Method, route) {
+				continue
+			}
+
+			window := time.Duration(rule.WindowSecond) * time.Second
+			if window <= 0 {
+				window = time.Second
+			}
+
+			key := fmt.Sprintf("%s:%s", rule.Name, rateLimitIdentity(c, rule.Key))
+			result, err := store.Take(c.Request.Context(), key, rule.MaxRequest, window)
+			if err != nil {
+				logrus.WithContext(c.Request.Context()).Errorf("rate limit store: %v", err)
+				continue
+			}
+
+			if tightest == nil || !result.Allowed || (tightest.Allowed && result.Remaining < tightest.Remaining) {
+				tightest = result
+			}
+			if !result.Allowed {
+				metrics.RateLimitRejections.WithLabelValues(rule.Name).Inc()
+				break
+			}
+		}
+
+		if tightest == nil {
+			c.Next()
+			return
+		}
+
+		c.Set(constants.RateLimit, tightest)
+		setRateLimitHeaders(c, tightest)
+		if !tightest.Allowed {
+			responseError(c, http.StatusTooManyRequests, errConstant.ErrTooManyRequests.Error())
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package middlewares
+
+import (
+	clients "field-service/clients/user"
+	"field-service/common/ratelimit"
+	"field-service/config"
+	"field-service/constants"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func setRateLimitRules(t *testing.T, rules ...config.RateLimitRule) {
+	t.Helper()
+	previous := config.Config.RateLimit.Rules
+	config.Config.RateLimit.Rules = rules
+	t.Cleanup(func() { config.Config.RateLimit.Rules = previous })
+}
+
+func newRateLimitRouter(handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handlers = append(handlers, func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/v1/field", handlers...)
+	router.GET("/api/v1/time", handlers...)
+	return router
+}
+
+func TestRateLimiterHeaders(t *testing.T) {
+	setRateLimitRules(t, config.RateLimitRule{
+		Name:         "ip",
+		Route:        "GET /api/v1/field",
+		Key:          rateLimitByIP,
+		MaxRequest:   2,
+		WindowSecond: 60,
+	})
+	router := newRateLimitRouter(IPRateLimiter(ratelimit.NewMemoryStore()))
+
+	tests := []struct {
+		path       string
+		code       int
+		remaining  string
+		retryAfter bool
+	}{
+		{path: "/api/v1/field", code: http.StatusOK, remaining: "1"},
+		{path: "/api/v1/field", code: http.StatusOK, remaining: "0"},
+		{path: "/api/v1/field", code: http.StatusTooManyRequests, remaining: "0", retryAfter: true},
+		{path: "/api/v1/time", code: http.StatusOK},
+	}
+	for i, test := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if recorder.Code != test.code {
+			t.Errorf("request %d: got %d, want %d", i, recorder.Code, test.code)
+		}
+		if got := recorder.Header().Get(constants.RateLimitRemaining); got != test.remaining {
+			t.Errorf("request %d: got remaining %q, want %q", i, got, test.remaining)
+		}
+		if test.remaining != "" && recorder.Header().Get(constants.RateLimitLimit) != "2" {
+			t.Errorf("request %d: got limit %q, want 2", i, recorder.Header().Get(constants.RateLimitLimit))
+		}
+		if got := recorder.Header().Get(constants.RetryAfter) != ""; got != test.retryAfter {
+			t.Errorf("request %d: got retry-after %t, want %t", i, got, test.retryAfter)
+		}
+	}
+}
+
+func TestRateLimiterKeepsTightestResult(t *testing.T) {
+	setRateLimitRules(t,
+		config.RateLimitRule{Name: "ip", Route: "* /*", Key: rateLimitByIP, MaxRequest: 1, WindowSecond: 60},
+		config.RateLimitRule{Name: "user", Route: "* /*", Key: rateLimitByUser, MaxRequest: 10, WindowSecond: 60},
+	)
+	store := ratelimit.NewMemoryStore()
+	router := newRateLimitRouter(IPRateLimiter(store), RateLimiter(store))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/field", nil))
+	if got := recorder.Header().Get(constants.RateLimitLimit); got != "1" {
+		t.Errorf("got limit %q, want the tighter IP rule", got)
+	}
+}
+
+func TestRateLimitIdentity(t *testing.T) {
+	userUUID := uuid.New()
+	tests := []struct {
+		name    string
+		key     string
+		user    *clients.UserData
+		service string
+		want    string
+	}{
+		{name: "user", key: rateLimitByUser, user: &clients.UserData{UUID: userUUID}, service: "order-service",
+			want: "user:" + userUUID.String()},
+		{name: "user falls back to service", key: rateLimitByUser, service: "order-service",
+			want: "service:order-service"},
+		{name: "user falls back to ip", key: rateLimitByUser, want: "ip:192.0.2.1"},
+		{name: "service", key: rateLimitByService, user: &clients.UserData{UUID: userUUID}, service: "order-service",
+			want: "service:order-service"},
+		{name: "service falls back to ip", key: rateLimitByService, want: "ip:192.0.2.1"},
+		{name: "ip", key: rateLimitByIP, user: &clients.UserData{UUID: userUUID}, service: "order-service",
+			want: "ip:192.0.2.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.service != "" {
+				c.Request.Header.Set(constants.XServiceName, test.service)
+			}
+			if test.user != nil {
+				c.Set(constants.User, test.user)
+			}
+
+			if got := rateLimitIdentity(c, test.key); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckRateLimitRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    config.RateLimitRule
+		wantErr bool
+	}{
+		{name: "valid", rule: config.RateLimitRule{Name: "a", Route: "* /*", Key: rateLimitByService}},
+		{name: "unknown key", rule: config.RateLimitRule{Name: "a", Route: "* /*", Key: "token"}, wantErr: true},
+		{name: "missing key", rule: config.RateLimitRule{Name: "a", Route: "* /*"}, wantErr: true},
+		{name: "missing name", rule: config.RateLimitRule{Route: "* /*", Key: rateLimitByIP}, wantErr: true},
+		{name: "missing method", rule: config.RateLimitRule{Name: "a", Route: "/*", Key: rateLimitByIP}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setRateLimitRules(t, test.rule)
+			if err := CheckRateLimitRules(); (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"errors"
+	"field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func findService(name string) (*config.Service, bool) {
+	for i, service := range config.Config.Services {
+		if service.Name == name {
+			return &config.Config.Services[i], true
+		}
+	}
+	return nil, false
+}
+
+// unscoped lets callers signing with the shared key call every route until
+// apiKey.sharedKeyScopes restricts them.
+var unscoped = []string{"* /*"}
+
+// CheckServices fails on signature keys that would let anybody sign: an empty
+// shared key while it is enabled, or a registered caller without keys or with
+// an empty one.
+func CheckServices() error {
+	if !config.Config.APIKey.DisableSharedKey && config.Config.SignatureKey == "" {
+		return errors.New("signatureKey is empty, set it or disable it with apiKey.disableSharedKey")
+	}
+
+	for _, service := range config.Config.Services {
+		if len(service.SignatureKeys) == 0 {
+			return fmt.Errorf("service %q has no signature keys", service.Name)
+		}
+		if slices.Contains(service.SignatureKeys, "") {
+			return fmt.Errorf("service %q has an empty signature key", service.Name)
+		}
+	}
+	return nil
+}
+
+// signatureKeys returns the keys a caller may sign with: its own while it is
+// registered, the shared key otherwise, unless that is disabled.
+func signatureKeys(serviceName string) []string {
+	service, ok := findService(serviceName)
+	if ok {
+		return service.SignatureKeys
+	}
+
+	if config.Config.APIKey.DisableSharedKey {
+		return nil
+	}
+	return []string{config.Config.SignatureKey}
+}
+
+// serviceScopes returns the routes a caller may call.
+func serviceScopes(serviceName string) []string {
+	service, ok := findService(serviceName)
+	if ok {
+		return service.Scopes
+	}
+
+	if len(config.Config.APIKey.SharedKeyScopes) > 0 {
+		return config.Config.APIKey.SharedKeyScopes
+	}
+	return unscoped
+}
+
+func matchScope(scope, method, route string) bool {
+	scopeMethod, pattern, ok := strings.Cut(strings.TrimSpace(scope), " ")
+	if !ok {
+		return false
+	}
+
+	if scopeMethod != "*" && !strings.EqualFold(scopeMethod, method) {
+		return false
+	}
+
+	pattern = strings.TrimSpace(pattern)
+	if prefix, wildcard := strings.CutSuffix(pattern, "*"); wildcard {
+		return strings.HasPrefix(route, prefix)
+	}
+	return pattern == route
+}
+
+// checkServiceScope rejects callers calling a route outside their scopes.
+// Routes are matched by their pattern, e.g. /api/v1/field/:uuid.
+func checkServiceScope(c *gin.Context) error {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+
+	for _, scope := range serviceScopes(c.GetHeader(constants.XServiceName)) {
+		if matchScope(scope, c.Request.Method, route) {
+			return nil
+		}
+	}
+	return errConstant.ErrForbidden
+}
@@ -0,0 +1,159 @@
+package middlewares
+
+import (
+	"field-service/config"
+	"field-service/constants"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMatchScope(t *testing.T) {
+	tests := []struct {
+		scope  string
+		method string
+		route  string
+		want   bool
+	}{
+		{scope: "GET /api/v1/field", method: "GET", route: "/api/v1/field", want: true},
+		{scope: "get /api/v1/field", method: "GET", route: "/api/v1/field", want: true},
+		{scope: "GET /api/v1/field", method: "POST", route: "/api/v1/field", want: false},
+		{scope: "GET /api/v1/field", method: "GET", route: "/api/v1/field/:uuid", want: false},
+		{scope: "* /api/v1/field", method: "DELETE", route: "/api/v1/field", want: true},
+		{scope: "GET /api/v1/field*", method: "GET", route: "/api/v1/field/schedule", want: true},
+		{scope: "GET /api/v1/field/*", method: "GET", route: "/api/v1/field", want: false},
+		{scope: "* /*", method: "PATCH", route: "/anything", want: true},
+		{scope: "  GET   /api/v1/time  ", method: "GET", route: "/api/v1/time", want: true},
+		{scope: "/api/v1/field", method: "GET", route: "/api/v1/field", want: false},
+		{scope: "", method: "GET", route: "/", want: false},
+	}
+	for _, test := range tests {
+		if got := matchScope(test.scope, test.method, test.route); got != test.want {
+			t.Errorf("matchScope(%q, %q, %q) = %t, want %t", test.scope, test.method, test.route, got, test.want)
+		}
+	}
+}
+
+func TestCheckServices(t *testing.T) {
+	previous := config.Config
+	t.Cleanup(func() { config.Config = previous })
+
+	tests := []struct {
+		name             string
+		signatureKey     string
+		disableSharedKey bool
+		services         []config.Service
+		wantErr          bool
+	}{
+		{name: "shared key", signatureKey: "key"},
+		{name: "empty shared key", wantErr: true},
+		{name: "disabled shared key", disableSharedKey: true},
+		{
+			name:         "service keys",
+			signatureKey: "key",
+			services:     []config.Service{{Name: "order-service", SignatureKeys: []string{"a", "b"}}},
+		},
+		{
+			name:         "service without keys",
+			signatureKey: "key",
+			services:     []config.Service{{Name: "order-service"}},
+			wantErr:      true,
+		},
+		{
+			name:         "service with an empty key",
+			signatureKey: "key",
+			services:     []config.Service{{Name: "order-service", SignatureKeys: []string{"a", ""}}},
+			wantErr:      true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config.Config.SignatureKey = test.signatureKey
+			config.Config.APIKey.DisableSharedKey = test.disableSharedKey
+			config.Config.Services = test.services
+
+			if err := CheckServices(); (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckServiceScope(t *testing.T) {
+	previous := config.Config
+	t.Cleanup(func() { config.Config = previous })
+
+	orderService := config.Service{
+		Name:          "order-service",
+		SignatureKeys: []string{"key"},
+		Scopes:        []string{"PATCH /api/v1/field/schedule/status"},
+	}
+	tests := []struct {
+		name            string
+		sharedKeyScopes []string
+		services        []config.Service
+		serviceName     string
+		method          string
+		path            string
+		want            bool
+	}{
+		{name: "shared key read", serviceName: "other", method: http.MethodGet, path: "/api/v1/field", want: true},
+		{name: "shared key write", serviceName: "other", method: http.MethodPatch, path: "/api/v1/field/schedule/status", want: true},
+		{
+			name:            "shared key in its scopes",
+			sharedKeyScopes: []string{"GET /api/v1/field*"},
+			serviceName:     "other",
+			method:          http.MethodGet,
+			path:            "/api/v1/field",
+			want:            true,
+		},
+		{
+			name:            "shared key outside its scopes",
+			sharedKeyScopes: []string{"GET /api/v1/field*"},
+			serviceName:     "other",
+			method:          http.MethodPatch,
+			path:            "/api/v1/field/schedule/status",
+			want:            false,
+		},
+		{
+			name:        "service in its scopes",
+			services:    []config.Service{orderService},
+			serviceName: "order-service",
+			method:      http.MethodPatch,
+			path:        "/api/v1/field/schedule/status",
+			want:        true,
+		},
+		{
+			name:        "service outside its scopes",
+			services:    []config.Service{orderService},
+			serviceName: "order-service",
+			method:      http.MethodGet,
+			path:        "/api/v1/field",
+			want:        false,
+		},
+		{
+			name:        "service without scopes",
+			services:    []config.Service{{Name: "order-service", SignatureKeys: []string{"key"}}},
+			serviceName: "order-service",
+			method:      http.MethodGet,
+			path:        "/api/v1/field",
+			want:        false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config.Config.APIKey.SharedKeyScopes = test.sharedKeyScopes
+			config.Config.Services = test.services
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(test.method, test.path, nil)
+			c.Request.Header.Set(constants.XServiceName, test.serviceName)
+
+			if got := checkServiceScope(c) == nil; got != test.want {
+				t.Errorf("got allowed %t, want %t", got, test.want)
+			}
+		})
+	}
+}
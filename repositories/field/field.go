@@ -1,139 +1,308 @@
-package repositories
-
-import (
-	"context"
-	"errors"
-	errWrap "field-service/common/error"
-	errConstant "field-service/constants/error"
-	errField "field-service/constants/error/field"
-	"field-service/domain/dto"
-	"field-service/domain/models"
-	"fmt"
-
-	"github.com/google/uuid"
-	"gorm.io/gorm"
-)
-
-type FieldRepository struct {
-	db *gorm.DB
-}
-
-type IFieldRepository interface {
-	FindAllWithPagination(context.Context, *dto.FieldRequestParam) ([]models.Field, int64, error)
-	FindAllWithoutPagination(context.Context) ([]models.Field, error)
-	FindByUUID(context.Context, string) (*models.Field, error)
-	Create(context.Context, *models.Field) (*models.Field, error)
-	Update(context.Context, string, *models.Field) (*models.Field, error)
-	Delete(context.Context, string) error
-}
-
-func NewFieldRepository(db *gorm.DB) IFieldRepository {
-	return &FieldRepository{db: db}
-}
-
-func (f *FieldRepository) FindAllWithPagination(
-	ctx context.Context,
-	param *dto.FieldRequestParam,
-) ([]models.Field, int64, error) {
-	var (
-		fields []models.Field
-		sort   string
-		total  int64
-	)
-	if param.SortColumn != nil {
-		sort = fmt.Sprintf("%s %s", *param.SortColumn, *param.SortOrder)
-	} else {
-		sort = "created_at desc"
-	}
-
-	limit := param.Limit
-	offset := (param.Page - 1) * limit
-	err := f.db.
-		WithContext(ctx).
-		Limit(limit).
-		Offset(offset).
-		Order(sort).
-		Find(&fields).
-		Error
-	if err != nil {
-		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
-	}
-
-	err = f.db.
-		WithContext(ctx).
-		Model(&fields).
-		Count(&total).
-		Error
-	if err != nil {
-		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
-	}
-
-	return fields, total, nil
-}
-
-func (f *FieldRepository) FindAllWithoutPagination(ctx context.Context) ([]models.Field, error) {
-	var fields []models.Field
-	err := f.db.
-		WithContext(ctx).
-		Find(&fields).
-		Error
-	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
-	}
-	return fields, nil
-}
-
-func (f *FieldRepository) FindByUUID(ctx context.Context, uuid string) (*models.Field, error) {
-	var field models.Field
-	err := f.db.
-		WithContext(ctx).
-		Where("uuid = ?", uuid).
-		First(&field).
-		Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errWrap.WrapError(errField.ErrFieldNotFound)
-		}
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
-	}
-	return &field, nil
-}
-
-func (f *FieldRepository) Create(ctx context.Context, req *models.Field) (*models.Field, error) {
-	field := models.Field{
-		UUID:         uuid.New(),
-		Code:         req.Code,
-		Name:         req.Name,
-		Images:       req.Images,
-		PricePerHour: req.PricePerHour,
-	}
-
-	err := f.db.WithContext(ctx).Create(&field).Error
-	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
-	}
-	return &field, nil
-}
-
-func (f *FieldRepository) Update(ctx context.Context, uuid string, req *models.Field) (*models.Field, error) {
-	field := models.Field{
-		Code:         req.Code,
-		Name:         req.Name,
-		Images:       req.Images,
-		PricePerHour: req.PricePerHour,
-	}
-
-	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Updates(&field).Error
-	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
-	}
-	return &field, nil
-}
-
-func (f *FieldRepository) Delete(ctx context.Context, uuid string) error {
-	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Delete(&models.Field{}).Error
-	if err != nil {
-		return errWrap.WrapError(errConstant.ErrSQLError)
-	}
-	return nil
-}
+package repositories
+
+import (
+	"context"
+	"errors"
+	errWrap "field-service/common/error"
+	"field-service/common/sorting"
+	"field-service/common/util"
+	errConstant "field-service/constants/error"
+	errField "field-service/constants/error/field"
+	"field-service/domain/dto"
+	"field-service/domain/models"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fieldSearchDocument is the weighted tsvector searched by Search, stored in
+// fields.search_vector by Migrate. Name and code rank above description,
+// which ranks above attributes.
+const fieldSearchDocument = `setweight(to_tsvector('simple'::regconfig, coalesce(name, '')), 'A') || ` +
+	`setweight(to_tsvector('simple'::regconfig, coalesce(code, '')), 'A') || ` +
+	`setweight(to_tsvector('simple'::regconfig, coalesce(description, '')), 'B') || ` +
+	`setweight(to_tsvector('simple'::regconfig, coalesce(field_attributes_text(attributes), '')), 'C')`
+
+type FieldRepository struct {
+	db *gorm.DB
+}
+
+type IFieldRepository interface {
+	FindAllWithPagination(context.Context, *dto.FieldRequestParam) ([]models.Field, int64, error)
+	FindAllWithCursor(context.Context, *dto.FieldRequestParam, *util.Cursor) ([]models.Field, *util.Cursor, error)
+	FindAllWithoutPagination(context.Context) ([]models.Field, error)
+	Search(context.Context, *dto.FieldSearchRequestParam) ([]models.Field, int64, error)
+	FindByUUID(context.Context, string) (*models.Field, error)
+	Create(context.Context, *models.Field) (*models.Field, error)
+	Update(context.Context, string, *models.Field) (*models.Field, error)
+	ModifyImages(context.Context, string, func([]string) ([]string, error)) (*models.Field, error)
+	FindAllImages(context.Context) ([]string, error)
+	Delete(context.Context, string) error
+}
+
+func NewFieldRepository(db *gorm.DB) IFieldRepository {
+	return &FieldRepository{db: db}
+}
+
+func (f *FieldRepository) FindAllWithPagination(
+	ctx context.Context,
+	param *dto.FieldRequestParam,
+) ([]models.Field, int64, error) {
+	var (
+		fields []models.Field
+		total  int64
+	)
+
+	limit := param.Limit
+	offset := (param.Page - 1) * limit
+	err := f.db.
+		WithContext(ctx).
+		Limit(limit).
+		Offset(offset).
+		Scopes(sorting.Scope(param.Orders, sorting.Order{Column: "fields.created_at", Desc: true})).
+		Find(&fields).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	err = f.db.
+		WithContext(ctx).
+		Model(&fields).
+		Count(&total).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return fields, total, nil
+}
+
+func (f *FieldRepository) FindAllWithCursor(
+	ctx context.Context,
+	param *dto.FieldRequestParam,
+	after *util.Cursor,
+) ([]models.Field, *util.Cursor, error) {
+	var fields []models.Field
+
+	err := f.db.
+		WithContext(ctx).
+		Scopes(util.KeysetScope("fields", after)).
+		Limit(param.Limit + 1).
+		Find(&fields).
+		Error
+	if err != nil {
+		return nil, nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	if len(fields) <= param.Limit {
+		return fields, nil, nil
+	}
+
+	fields = fields[:param.Limit]
+	last := fields[len(fields)-1]
+	return fields, &util.Cursor{CreatedAt: *last.CreatedAt, ID: last.ID}, nil
+}
+
+func (f *FieldRepository) FindAllWithoutPagination(ctx context.Context) ([]models.Field, error) {
+	var fields []models.Field
+	err := f.db.
+		WithContext(ctx).
+		Find(&fields).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return fields, nil
+}
+
+// toPrefixQuery turns free text into a tsquery matching every term as a
+// prefix, e.g. "futsal ind" becomes "futsal:* & ind:*". Anything other than
+// letters and digits is dropped so user input can't inject tsquery operators.
+func toPrefixQuery(text string) string {
+	terms := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+func (f *FieldRepository) Search(
+	ctx context.Context,
+	param *dto.FieldSearchRequestParam,
+) ([]models.Field, int64, error) {
+	var (
+		fields []models.Field
+		total  int64
+	)
+
+	query := toPrefixQuery(param.Query)
+	if query == "" {
+		return fields, 0, nil
+	}
+
+	match := "fields.search_vector @@ to_tsquery('simple', ?)"
+	rank := clause.OrderBy{
+		Expression: clause.Expr{
+			SQL:                "ts_rank(fields.search_vector, to_tsquery('simple', ?)) desc, fields.created_at desc",
+			Vars:               []interface{}{query},
+			WithoutParentheses: true,
+		},
+	}
+
+	limit := param.Limit
+	offset := (param.Page - 1) * limit
+	err := f.db.
+		WithContext(ctx).
+		Where(match, query).
+		Limit(limit).
+		Offset(offset).
+		Order(rank).
+		Find(&fields).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	err = f.db.
+		WithContext(ctx).
+		Model(&models.Field{}).
+		Where(match, query).
+		Count(&total).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return fields, total, nil
+}
+
+func (f *FieldRepository) FindByUUID(ctx context.Context, uuid string) (*models.Field, error) {
+	var field models.Field
+	err := f.db.
+		WithContext(ctx).
+		Where("uuid = ?", uuid).
+		First(&field).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return &field, nil
+}
+
+func (f *FieldRepository) Create(ctx context.Context, req *models.Field) (*models.Field, error) {
+	field := models.Field{
+		UUID:         uuid.New(),
+		Code:         req.Code,
+		Name:         req.Name,
+		Description:  req.Description,
+		Attributes:   req.Attributes,
+		Images:       req.Images,
+		PricePerHour: req.PricePerHour,
+		OwnerUUID:    req.OwnerUUID,
+	}
+
+	err := f.db.WithContext(ctx).Create(&field).Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return &field, nil
+}
+
+func (f *FieldRepository) Update(ctx context.Context, uuid string, req *models.Field) (*models.Field, error) {
+	field := models.Field{
+		Code:         req.Code,
+		Name:         req.Name,
+		Description:  req.Description,
+		Attributes:   req.Attributes,
+		Images:       req.Images,
+		PricePerHour: req.PricePerHour,
+		OwnerUUID:    req.OwnerUUID,
+	}
+
+	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Updates(&field).Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return &field, nil
+}
+
+// ModifyImages replaces the images of a field with what modify returns for
+// the current ones. The row stays locked in between, so concurrent changes to
+// the same field are applied one after another instead of overwriting each
+// other. Errors from modify are returned as is and leave the field untouched.
+func (f *FieldRepository) ModifyImages(
+	ctx context.Context,
+	uuid string,
+	modify func([]string) ([]string, error),
+) (*models.Field, error) {
+	var field models.Field
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("uuid = ?", uuid).
+			First(&field).
+			Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+			}
+			return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+		}
+
+		images, err := modify(field.Images)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&field).Update("images", pq.StringArray(images)).Error
+		if err != nil {
+			return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+		}
+		field.Images = images
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+// FindAllImages returns the images of every field, soft-deleted ones included
+// since their images may still be restored.
+func (f *FieldRepository) FindAllImages(ctx context.Context) ([]string, error) {
+	var fieldImages []pq.StringArray
+	err := f.db.
+		WithContext(ctx).
+		Unscoped().
+		Model(&models.Field{}).
+		Pluck("images", &fieldImages).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	images := make([]string, 0, len(fieldImages))
+	for _, fieldImage := range fieldImages {
+		images = append(images, fieldImage...)
+	}
+	return images, nil
+}
+
+func (f *FieldRepository) Delete(ctx context.Context, uuid string) error {
+	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Delete(&models.Field{}).Error
+	if err != nil {
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return nil
+}
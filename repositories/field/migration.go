@@ -0,0 +1,25 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// searchMigrations store the search document of Search as a generated column
+// with a GIN index. array_to_string is only stable, so the attributes go
+// through an immutable wrapper a generated column may call.
+var searchMigrations = []string{
+	`CREATE OR REPLACE FUNCTION field_attributes_text(text[]) RETURNS text
+		LANGUAGE sql IMMUTABLE AS $$ SELECT array_to_string($1, ' ') $$`,
+	`ALTER TABLE fields ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (` + fieldSearchDocument + `) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_fields_search_vector ON fields USING GIN (search_vector)`,
+}
+
+// Migrate adds what AutoMigrate cannot express to the fields table.
+func Migrate(db *gorm.DB) error {
+	for _, statement := range searchMigrations {
+		err := db.Exec(statement).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"errors"
 	errWrap "field-service/common/error"
+	"field-service/common/sorting"
+	"field-service/common/util"
 	"field-service/constants"
 	errConstant "field-service/constants/error"
 	errFieldSchedule "field-service/constants/error/fieldschedule"
 	"field-service/domain/dto"
 	"field-service/domain/models"
-	"fmt"
 	"gorm.io/gorm"
 )
 
@@ -19,33 +20,64 @@ type FieldScheduleRepository struct {
 
 type IFieldScheduleRepository interface {
 	FindAllWithPagination(context.Context, *dto.FieldScheduleRequestParam) ([]models.FieldSchedule, int64, error)
+	FindAllWithCursor(context.Context, *dto.FieldScheduleRequestParam, *util.Cursor) ([]models.FieldSchedule, *util.Cursor, error)
 	FindAllByFieldIDAndDate(context.Context, int, string) ([]models.FieldSchedule, error)
 	FindByUUID(context.Context, string) (*models.FieldSchedule, error)
 	FindByDateAndTimeID(context.Context, string, int, int) (*models.FieldSchedule, error)
 	Create(context.Context, []models.FieldSchedule) error
 	Update(context.Context, string, *models.FieldSchedule) (*models.FieldSchedule, error)
-	UpdateStatus(context.Context, constants.FieldScheduleStatus, string) error
+	UpdateStatus(context.Context, constants.FieldScheduleStatus, string) (bool, error)
 	Delete(context.Context, string) error
+	CountAvailableByField(context.Context, string, string) ([]dto.FieldAvailableSlotCount, error)
 }
 
 func NewFieldScheduleRepository(db *gorm.DB) IFieldScheduleRepository {
 	return &FieldScheduleRepository{db: db}
 }
 
+// filter narrows a schedule query down to the optional filters of param. It
+// is shared by the find and count queries so the total matches the page.
+func (f *FieldScheduleRepository) filter(param *dto.FieldScheduleRequestParam) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(param.FieldIDs) > 0 {
+			db = db.Where("field_schedules.field_id IN (?)", f.db.
+				Model(&models.Field{}).
+				Select("id").
+				Where("uuid IN ?", param.FieldIDs))
+		}
+
+		if param.TimeID != nil {
+			db = db.Where("field_schedules.time_id IN (?)", f.db.
+				Model(&models.Time{}).
+				Select("id").
+				Where("uuid = ?", *param.TimeID))
+		}
+
+		if param.DateFrom != nil {
+			db = db.Where("field_schedules.date >= ?", *param.DateFrom)
+		}
+
+		if param.DateTo != nil {
+			db = db.Where("field_schedules.date <= ?", *param.DateTo)
+		}
+
+		if param.Status != nil {
+			status := constants.FieldScheduleStatusName(*param.Status).GetStatusInt()
+			db = db.Where("field_schedules.status = ?", status)
+		}
+
+		return db
+	}
+}
+
 func (f *FieldScheduleRepository) FindAllWithPagination(
 	ctx context.Context,
 	param *dto.FieldScheduleRequestParam,
 ) ([]models.FieldSchedule, int64, error) {
 	var (
 		fieldSchedules []models.FieldSchedule
-		sort           string
 		total          int64
 	)
-	if param.SortColumn != nil {
-		sort = fmt.Sprintf("%s %s", *param.SortColumn, *param.SortOrder)
-	} else {
-		sort = "created_at desc"
-	}
 
 	limit := param.Limit
 	offset := (param.Page - 1) * limit
@@ -53,27 +85,59 @@ func (f *FieldScheduleRepository) FindAllWithPagination(
 		WithContext(ctx).
 		Preload("Field").
 		Preload("Time").
+		Joins("LEFT JOIN fields ON fields.id = field_schedules.field_id").
+		Joins("LEFT JOIN times ON times.id = field_schedules.time_id").
+		Scopes(f.filter(param)).
 		Limit(limit).
 		Offset(offset).
-		Order(sort).
+		Scopes(sorting.Scope(param.Orders, sorting.Order{Column: "field_schedules.created_at", Desc: true})).
 		Find(&fieldSchedules).
 		Error
 	if err != nil {
-		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	err = f.db.
 		WithContext(ctx).
 		Model(&fieldSchedules).
+		Scopes(f.filter(param)).
 		Count(&total).
 		Error
 	if err != nil {
-		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return fieldSchedules, total, nil
 }
 
+func (f *FieldScheduleRepository) FindAllWithCursor(
+	ctx context.Context,
+	param *dto.FieldScheduleRequestParam,
+	after *util.Cursor,
+) ([]models.FieldSchedule, *util.Cursor, error) {
+	var fieldSchedules []models.FieldSchedule
+
+	err := f.db.
+		WithContext(ctx).
+		Preload("Field").
+		Preload("Time").
+		Scopes(f.filter(param), util.KeysetScope("field_schedules", after)).
+		Limit(param.Limit + 1).
+		Find(&fieldSchedules).
+		Error
+	if err != nil {
+		return nil, nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	if len(fieldSchedules) <= param.Limit {
+		return fieldSchedules, nil, nil
+	}
+
+	fieldSchedules = fieldSchedules[:param.Limit]
+	last := fieldSchedules[len(fieldSchedules)-1]
+	return fieldSchedules, &util.Cursor{CreatedAt: *last.CreatedAt, ID: last.ID}, nil
+}
+
 func (f *FieldScheduleRepository) FindAllByFieldIDAndDate(
 	ctx context.Context,
 	fieldID int,
@@ -91,7 +155,7 @@ func (f *FieldScheduleRepository) FindAllByFieldIDAndDate(
 		Find(&fieldSchedules).
 		Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return fieldSchedules, nil
 }
@@ -107,9 +171,9 @@ func (f *FieldScheduleRepository) FindByUUID(ctx context.Context, uuid string) (
 		Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errWrap.WrapError(errFieldSchedule.ErrFieldScheduleNotFound)
+			return nil, errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleNotFound)
 		}
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return &fieldSchedule, nil
 }
@@ -131,7 +195,7 @@ func (f *FieldScheduleRepository) FindByDateAndTimeID(
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return &fieldSchedule, nil
 }
@@ -139,7 +203,7 @@ func (f *FieldScheduleRepository) FindByDateAndTimeID(
 func (f *FieldScheduleRepository) Create(ctx context.Context, req []models.FieldSchedule) error {
 	err := f.db.WithContext(ctx).Create(&req).Error
 	if err != nil {
-		return errWrap.WrapError(errConstant.ErrSQLError)
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return nil
 }
@@ -157,33 +221,66 @@ func (f *FieldScheduleRepository) Update(
 	fieldSchedule.Date = req.Date
 	err = f.db.WithContext(ctx).Save(&fieldSchedule).Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return fieldSchedule, nil
 }
 
+// UpdateStatus reports whether the schedule changed, which it does not when
+// it already had status. The check is part of the update, so concurrent
+// requests cannot both see the schedule change.
 func (f *FieldScheduleRepository) UpdateStatus(
 	ctx context.Context,
 	status constants.FieldScheduleStatus,
 	uuid string,
-) error {
-	fieldSchedule, err := f.FindByUUID(ctx, uuid)
+) (bool, error) {
+	_, err := f.FindByUUID(ctx, uuid)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	fieldSchedule.Status = status
-	err = f.db.WithContext(ctx).Save(&fieldSchedule).Error
-	if err != nil {
-		return errWrap.WrapError(errConstant.ErrSQLError)
+	result := f.db.WithContext(ctx).
+		Model(&models.FieldSchedule{}).
+		Where("uuid = ? AND status <> ?", uuid, status).
+		Update("status", status)
+	if result.Error != nil {
+		return false, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
-	return nil
+	return result.RowsAffected > 0, nil
 }
 
 func (f *FieldScheduleRepository) Delete(ctx context.Context, uuid string) error {
 	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Delete(&models.FieldSchedule{}).Error
 	if err != nil {
-		return errWrap.WrapError(errConstant.ErrSQLError)
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return nil
 }
+
+// CountAvailableByField counts the available schedules between dateFrom and
+// dateTo, inclusive, of every field; fields without any count zero.
+func (f *FieldScheduleRepository) CountAvailableByField(
+	ctx context.Context,
+	dateFrom, dateTo string,
+) ([]dto.FieldAvailableSlotCount, error) {
+	var counts []dto.FieldAvailableSlotCount
+	err := f.db.
+		WithContext(ctx).
+		Model(&models.Field{}).
+		Select("fields.uuid AS field_uuid, fields.name AS field_name, COUNT(field_schedules.id) AS available").
+		Joins(
+			"LEFT JOIN field_schedules ON field_schedules.field_id = fields.id "+
+				"AND field_schedules.status = ? AND field_schedules.date BETWEEN ? AND ?",
+			constants.Available,
+			dateFrom,
+			dateTo,
+		).
+		Where("fields.deleted_at IS NULL").
+		Group("fields.uuid, fields.name").
+		Scan(&counts).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return counts, nil
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// createdAtTables are walked by cursor pagination, which needs created_at on
+// every row.
+var createdAtTables = []string{"fields", "field_schedules"}
+
+// BackfillCreatedAt fills created_at where it is NULL, from updated_at when
+// there is one. It runs before AutoMigrate, which makes the columns NOT NULL
+// and fails while they still hold NULLs. Tables not created yet are skipped.
+func BackfillCreatedAt(db *gorm.DB) error {
+	for _, table := range createdAtTables {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+
+		err := db.Exec(fmt.Sprintf(
+			"UPDATE %s SET created_at = COALESCE(updated_at, now()) WHERE created_at IS NULL",
+			table,
+		)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
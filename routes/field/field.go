@@ -2,9 +2,7 @@ package routes
 
 import (
 	"field-service/clients"
-	"field-service/constants"
 	"field-service/controllers"
-	"field-service/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,24 +26,17 @@ func NewFieldRoute(controller controllers.IControllerRegistry, group *gin.Router
 
 func (f *FieldRoute) Run() {
 	group := f.group.Group("/field")
-	group.GET("", middlewares.AuthenticateWithoutToken(), f.controller.GetField().GetAllWithoutPagination)
-	group.GET("/:uuid", middlewares.AuthenticateWithoutToken(), f.controller.GetField().GetByUUID)
-	group.Use(middlewares.Authenticate())
-	group.GET("/pagination", middlewares.CheckRole([]string{
-		constants.Admin,
-		constants.Customer,
-	}, f.client),
-		f.controller.GetField().GetAllWithPagination)
-	group.POST("", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
-		f.controller.GetField().Create)
-	group.PUT("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
-		f.controller.GetField().Update)
-	group.DELETE("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
-		f.controller.GetField().Delete)
+	group.GET("", f.controller.GetField().GetAllWithoutPagination)
+	group.GET("/search", f.controller.GetField().Search)
+	group.GET("/:uuid", f.controller.GetField().GetByUUID)
+	group.GET("/pagination", f.controller.GetField().GetAllWithPagination)
+	group.POST("", f.controller.GetField().Create)
+	group.PUT("/:uuid", f.controller.GetField().Update)
+	group.DELETE("/:uuid", f.controller.GetField().Delete)
+	group.POST("/:uuid/images", f.controller.GetField().AddImages)
+	group.DELETE("/:uuid/images", f.controller.GetField().RemoveImage)
+	group.PUT("/:uuid/images/order", f.controller.GetField().ReorderImages)
+	group.PUT("/:uuid/images/cover", f.controller.GetField().SetCoverImage)
+	group.POST("/:uuid/images/presign", f.controller.GetField().PresignImageUpload)
+	group.POST("/:uuid/images/confirm", f.controller.GetField().ConfirmImageUpload)
 }
@@ -2,9 +2,7 @@ package routes
 
 import (
 	"field-service/clients"
-	"field-service/constants"
 	"field-service/controllers"
-	"field-service/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,33 +26,12 @@ func NewFieldScheduleRoute(controller controllers.IControllerRegistry, group *gi
 
 func (f *FieldScheduleRoute) Run() {
 	group := f.group.Group("/field/schedule")
-	group.GET("/lists/:uuid", middlewares.AuthenticateWithoutToken(), f.controller.GetFieldSchedule().GetAllByFieldIDAndDate)
-	group.PATCH("/status", middlewares.AuthenticateWithoutToken(), f.controller.GetFieldSchedule().UpdateStatus)
-	group.Use(middlewares.Authenticate())
-	group.GET("/pagination", middlewares.CheckRole([]string{
-		constants.Admin,
-		constants.Customer,
-	}, f.client),
-		f.controller.GetFieldSchedule().GetAllWithPagination)
-	group.GET("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-		constants.Customer,
-	}, f.client),
-		f.controller.GetFieldSchedule().GetByUUID)
-	group.POST("", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
-		f.controller.GetFieldSchedule().Create)
-	group.POST("/one-month", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
-		f.controller.GetFieldSchedule().GenerateScheduleForOneMonth)
-	group.PUT("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
-		f.controller.GetFieldSchedule().Update)
-	group.DELETE("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
-		f.controller.GetFieldSchedule().Delete)
+	group.GET("/lists/:uuid", f.controller.GetFieldSchedule().GetAllByFieldIDAndDate)
+	group.PATCH("/status", f.controller.GetFieldSchedule().UpdateStatus)
+	group.GET("/pagination", f.controller.GetFieldSchedule().GetAllWithPagination)
+	group.GET("/:uuid", f.controller.GetFieldSchedule().GetByUUID)
+	group.POST("", f.controller.GetFieldSchedule().Create)
+	group.POST("/one-month", f.controller.GetFieldSchedule().GenerateScheduleForOneMonth)
+	group.PUT("/:uuid", f.controller.GetFieldSchedule().Update)
+	group.DELETE("/:uuid", f.controller.GetFieldSchedule().Delete)
 }
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"field-service/config"
+	"field-service/constants"
+	"field-service/middlewares"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPolicies apply while routePolicies is not configured.
+var defaultPolicies = []config.RoutePolicy{
+	{Route: "GET /api/v1/field"},
+	{Route: "GET /api/v1/field/search"},
+	{Route: "GET /api/v1/field/pagination", Token: true, Permissions: []string{string(constants.FieldRead)}},
+	{Route: "GET /api/v1/field/:uuid"},
+	{Route: "* /api/v1/field/:uuid*", Token: true, Permissions: []string{string(constants.FieldWrite)}},
+	{Route: "POST /api/v1/field", Token: true, Permissions: []string{string(constants.FieldWrite)}},
+	{Route: "GET /api/v1/field/schedule/lists/:uuid"},
+	{Route: "PATCH /api/v1/field/schedule/status", Services: []string{"order-service"}},
+	{Route: "GET /api/v1/field/schedule/*", Token: true, Permissions: []string{string(constants.ScheduleRead)}},
+	{
+		Route:       "POST /api/v1/field/schedule/one-month",
+		Token:       true,
+		Permissions: []string{string(constants.ScheduleGenerate)},
+	},
+	{Route: "* /api/v1/field/schedule*", Token: true, Permissions: []string{string(constants.ScheduleWrite)}},
+	{Route: "GET /api/v1/time*", Token: true, Permissions: []string{string(constants.TimeRead)}},
+	{Route: "POST /api/v1/time", Token: true, Permissions: []string{string(constants.TimeWrite)}},
+}
+
+func routePolicies() []config.RoutePolicy {
+	if len(config.Config.RoutePolicies) == 0 {
+		return defaultPolicies
+	}
+	return config.Config.RoutePolicies
+}
+
+// checkPolicies makes sure the policies are well formed and that every route
+// under basePath has one, so a new route cannot go live unguarded.
+func checkPolicies(policies []config.RoutePolicy, routes gin.RoutesInfo, basePath string) error {
+	for _, policy := range policies {
+		if !strings.Contains(strings.TrimSpace(policy.Route), " ") {
+			return fmt.Errorf("route policy %q: route must be \"METHOD /route\"", policy.Route)
+		}
+
+		if !policy.Token && (len(policy.Roles) > 0 || len(policy.Permissions) > 0) {
+			return fmt.Errorf("route policy %q: roles and permissions need a token", policy.Route)
+		}
+
+		for _, permission := range policy.Permissions {
+			if !constants.IsPermission(constants.Permission(permission)) {
+				return fmt.Errorf("route policy %q: unknown permission %q", policy.Route, permission)
+			}
+		}
+	}
+
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, basePath) {
+			continue
+		}
+
+		if _, ok := middlewares.FindPolicy(policies, route.Method, route.Path); !ok {
+			return fmt.Errorf("no route policy for %s %s", route.Method, route.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"field-service/config"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckPolicies(t *testing.T) {
+	routes := gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/api/v1/field"},
+		{Method: http.MethodPost, Path: "/api/v1/field"},
+		{Method: http.MethodGet, Path: "/metrics"},
+	}
+
+	tests := []struct {
+		name     string
+		policies []config.RoutePolicy
+		wantErr  bool
+	}{
+		{
+			name:     "every route covered",
+			policies: []config.RoutePolicy{{Route: "GET /api/v1/field"}, {Route: "POST /api/v1/field", Token: true}},
+		},
+		{
+			name:     "wildcard",
+			policies: []config.RoutePolicy{{Route: "* /api/v1/*", Token: true, Permissions: []string{"field:read"}}},
+		},
+		{
+			name:     "route without policy",
+			policies: []config.RoutePolicy{{Route: "GET /api/v1/field"}},
+			wantErr:  true,
+		},
+		{
+			name:     "route without method",
+			policies: []config.RoutePolicy{{Route: "/api/v1/*"}},
+			wantErr:  true,
+		},
+		{
+			name:     "roles without token",
+			policies: []config.RoutePolicy{{Route: "* /api/v1/*", Roles: []string{"admin"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "permissions without token",
+			policies: []config.RoutePolicy{{Route: "* /api/v1/*", Permissions: []string{"field:read"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown permission",
+			policies: []config.RoutePolicy{{Route: "* /api/v1/*", Token: true, Permissions: []string{"field:own"}}},
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkPolicies(test.policies, routes, "/api/v1")
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultPolicies(t *testing.T) {
+	routes := gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/api/v1/field"},
+		{Method: http.MethodGet, Path: "/api/v1/field/search"},
+		{Method: http.MethodGet, Path: "/api/v1/field/pagination"},
+		{Method: http.MethodGet, Path: "/api/v1/field/:uuid"},
+		{Method: http.MethodPost, Path: "/api/v1/field"},
+		{Method: http.MethodPut, Path: "/api/v1/field/:uuid"},
+		{Method: http.MethodDelete, Path: "/api/v1/field/:uuid"},
+		{Method: http.MethodPost, Path: "/api/v1/field/:uuid/images"},
+		{Method: http.MethodGet, Path: "/api/v1/field/schedule/lists/:uuid"},
+		{Method: http.MethodPatch, Path: "/api/v1/field/schedule/status"},
+		{Method: http.MethodGet, Path: "/api/v1/field/schedule/pagination"},
+		{Method: http.MethodPost, Path: "/api/v1/field/schedule"},
+		{Method: http.MethodPost, Path: "/api/v1/field/schedule/one-month"},
+		{Method: http.MethodGet, Path: "/api/v1/time"},
+		{Method: http.MethodPost, Path: "/api/v1/time"},
+	}
+
+	err := checkPolicies(defaultPolicies, routes, "/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,7 +2,9 @@ package routes
 
 import (
 	"field-service/clients"
+	"field-service/common/ratelimit"
 	"field-service/controllers"
+	"field-service/middlewares"
 	fieldRoute "field-service/routes/field"
 	fieldScheduleRoute "field-service/routes/fieldschedule"
 	timeRoute "field-service/routes/time"
@@ -11,19 +13,29 @@ import (
 
 type Registry struct {
 	controller controllers.IControllerRegistry
+	router     *gin.Engine
 	group      *gin.RouterGroup
 	client     clients.IClientRegistry
+	rateLimit  ratelimit.Store
 }
 
 type IRegistry interface {
-	Serve()
+	Serve() error
 }
 
-func NewRouteRegistry(controller controllers.IControllerRegistry, group *gin.RouterGroup, client clients.IClientRegistry) IRegistry {
+func NewRouteRegistry(
+	controller controllers.IControllerRegistry,
+	router *gin.Engine,
+	group *gin.RouterGroup,
+	client clients.IClientRegistry,
+	rateLimit ratelimit.Store,
+) IRegistry {
 	return &Registry{
 		controller: controller,
+		router:     router,
 		group:      group,
 		client:     client,
+		rateLimit:  rateLimit,
 	}
 }
 
@@ -39,8 +51,29 @@ func (r *Registry) timeRoute() timeRoute.ITimeRoute {
 	return timeRoute.NewTimeRoute(r.controller, r.group, r.client)
 }
 
-func (r *Registry) Serve() {
+// Serve registers the routes behind their route policies and rate limits,
+// and fails on signature keys anybody could sign with, on malformed rate limit
+// rules or when a route has no policy.
+func (r *Registry) Serve() error {
+	err := middlewares.CheckServices()
+	if err != nil {
+		return err
+	}
+
+	err = middlewares.CheckRateLimitRules()
+	if err != nil {
+		return err
+	}
+
+	err = middlewares.CheckCORS()
+	if err != nil {
+		return err
+	}
+
+	policies := routePolicies()
+	r.group.Use(middlewares.Authorize(policies, r.client, r.rateLimit), middlewares.RateLimiter(r.rateLimit))
 	r.fieldRoute().Run()
 	r.fieldScheduleRoute().Run()
 	r.timeRoute().Run()
+	return checkPolicies(policies, r.router.Routes(), r.group.BasePath())
 }
@@ -2,9 +2,7 @@ package routes
 
 import (
 	"field-service/clients"
-	"field-service/constants"
 	"field-service/controllers"
-	"field-service/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,14 +26,7 @@ func NewTimeRoute(controller controllers.IControllerRegistry, group *gin.RouterG
 
 func (t *TimeRoute) Run() {
 	group := t.group.Group("/time")
-	group.Use(middlewares.Authenticate())
-	group.GET("", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, t.client), t.controller.GetTime().GetAll)
-	group.GET("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, t.client), t.controller.GetTime().GetByUUID)
-	group.POST("", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, t.client), t.controller.GetTime().Create)
+	group.GET("", t.controller.GetTime().GetAll)
+	group.GET("/:uuid", t.controller.GetTime().GetByUUID)
+	group.POST("", t.controller.GetTime().Create)
 }
@@ -1,243 +1,806 @@
-package services
-
-import (
-	"bytes"
-	"context"
-	"field-service/common/s3"
-	"field-service/common/util"
-	errConstant "field-service/constants/error"
-	"field-service/domain/dto"
-	"field-service/domain/models"
-	"field-service/repositories"
-	"fmt"
-	"io"
-	"mime/multipart"
-	"path"
-	"time"
-
-	"github.com/google/uuid"
-)
-
-type FieldService struct {
-	repository repositories.IRepositoryRegistry
-	s3Client   s3.IS3Client
-}
-
-type IFieldService interface {
-	GetAllWithPagination(context.Context, *dto.FieldRequestParam) (*util.PaginationResult, error)
-	GetAllWithoutPagination(context.Context) ([]dto.FieldResponse, error)
-	GetByUUID(context.Context, string) (*dto.FieldResponse, error)
-	Create(context.Context, *dto.FieldRequest) (*dto.FieldResponse, error)
-	Update(context.Context, string, *dto.UpdateFieldRequest) (*dto.FieldResponse, error)
-	Delete(context.Context, string) error
-}
-
-func NewFieldService(repository repositories.IRepositoryRegistry, s3Client s3.IS3Client) IFieldService {
-	return &FieldService{
-		repository: repository,
-		s3Client:   s3Client,
-	}
-}
-
-func (s *FieldService) GetAllWithPagination(ctx context.Context, param *dto.FieldRequestParam) (*util.PaginationResult, error) {
-	fields, total, err := s.repository.GetField().FindAllWithPagination(ctx, param)
-	if err != nil {
-		return nil, err
-	}
-
-	fieldResults := make([]*dto.FieldResponse, 0, len(fields))
-	for _, field := range fields {
-		fieldResults = append(fieldResults, &dto.FieldResponse{
-			UUID:         field.UUID,
-			Code:         field.Code,
-			Name:         field.Name,
-			PricePerHour: field.PricePerHour,
-			Images:       field.Images,
-			CreatedAt:    field.CreatedAt,
-			UpdatedAt:    field.UpdatedAt,
-		})
-	}
-
-	pagination := &util.PaginationParam{
-		Count: total,
-		Page:  param.Page,
-		Limit: param.Limit,
-		Data:  fieldResults,
-	}
-
-	response := util.GeneratePagination(*pagination)
-	return &response, nil
-}
-
-func (s *FieldService) GetAllWithoutPagination(ctx context.Context) ([]dto.FieldResponse, error) {
-	fields, err := s.repository.GetField().FindAllWithoutPagination(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	fieldResults := make([]dto.FieldResponse, 0, len(fields))
-	for _, field := range fields {
-		fieldResults = append(fieldResults, dto.FieldResponse{
-			UUID:         field.UUID,
-			Code:         field.Code,
-			Name:         field.Name,
-			PricePerHour: field.PricePerHour,
-			Images:       field.Images,
-		})
-	}
-
-	return fieldResults, nil
-}
-
-func (s *FieldService) GetByUUID(ctx context.Context, uuid string) (*dto.FieldResponse, error) {
-	field, err := s.repository.GetField().FindByUUID(ctx, uuid)
-	if err != nil {
-		return nil, err
-	}
-
-	fieldResult := dto.FieldResponse{
-		UUID:         field.UUID,
-		Code:         field.Code,
-		Name:         field.Name,
-		PricePerHour: field.PricePerHour,
-		Images:       field.Images,
-		CreatedAt:    field.CreatedAt,
-		UpdatedAt:    field.UpdatedAt,
-	}
-
-	return &fieldResult, nil
-}
-
-func (f *FieldService) validateUpload(images []multipart.FileHeader) error {
-	if images == nil || len(images) == 0 {
-		return errConstant.ErrInValidUploadFile
-	}
-
-	for _, image := range images {
-		if image.Size > 1024*1024*5 {
-			return errConstant.ErrSizeToBig
-		}
-	}
-
-	return nil
-}
-
-func (s *FieldService) processAndUploadImage(ctx context.Context, image multipart.FileHeader) (string, error) {
-	file, err := image.Open()
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	buffer := new(bytes.Buffer)
-	_, err = io.Copy(buffer, file)
-	if err != nil {
-		return "", err
-	}
-
-	filename := fmt.Sprintf("images/%s-%s%s", time.Now().Format("20060102150405"), image.Filename, path.Ext(image.Filename))
-	url, err := s.s3Client.UploadFile(ctx, filename, buffer.Bytes())
-	if err != nil {
-		return "", err
-	}
-	return url, nil
-}
-
-func (s *FieldService) uploadImage(ctx context.Context, images []multipart.FileHeader) ([]string, error) {
-	err := s.validateUpload(images)
-	if err != nil {
-		return nil, err
-	}
-
-	urls := make([]string, 0, len(images))
-	for _, image := range images {
-		url, err := s.processAndUploadImage(ctx, image)
-		if err != nil {
-			return nil, err
-		}
-		urls = append(urls, url)
-	}
-
-	return urls, nil
-}
-
-func (s *FieldService) Create(ctx context.Context, request *dto.FieldRequest) (*dto.FieldResponse, error) {
-	imageUrl, err := s.uploadImage(ctx, request.Images)
-	if err != nil {
-		return nil, err
-	}
-
-	field, err := s.repository.GetField().Create(ctx, &models.Field{
-		Code:         request.Code,
-		Name:         request.Name,
-		PricePerHour: request.PricePerHour,
-		Images:       imageUrl,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	response := &dto.FieldResponse{
-		UUID:         field.UUID,
-		Code:         field.Code,
-		Name:         field.Name,
-		PricePerHour: field.PricePerHour,
-		Images:       field.Images,
-		CreatedAt:    field.CreatedAt,
-		UpdatedAt:    field.UpdatedAt,
-	}
-	return response, nil
-}
-
-func (s *FieldService) Update(ctx context.Context, uuidParams string, request *dto.UpdateFieldRequest) (*dto.FieldResponse, error) {
-	field, err := s.repository.GetField().FindByUUID(ctx, uuidParams)
-	if err != nil {
-		return nil, err
-	}
-
-	var imageUrl []string
-	if request.Images == nil {
-		imageUrl = field.Images
-	} else {
-		imageUrl, err = s.uploadImage(ctx, request.Images)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	fieldResult, err := s.repository.GetField().Update(ctx, uuidParams, &models.Field{
-		Code:         request.Code,
-		Name:         request.Name,
-		PricePerHour: request.PricePerHour,
-		Images:       imageUrl,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	uuidParsed, _ := uuid.Parse(uuidParams)
-
-	return &dto.FieldResponse{
-		UUID:         uuidParsed,
-		Code:         fieldResult.Code,
-		Name:         fieldResult.Name,
-		PricePerHour: fieldResult.PricePerHour,
-		Images:       fieldResult.Images,
-		CreatedAt:    fieldResult.CreatedAt,
-		UpdatedAt:    fieldResult.UpdatedAt,
-	}, nil
-}
-
-func (s *FieldService) Delete(ctx context.Context, uuid string) error {
-	_, err := s.repository.GetField().FindByUUID(ctx, uuid)
-	if err != nil {
-		return err
-	}
-
-	err = s.repository.GetField().Delete(ctx, uuid)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
+package services
+
+import (
+	"bytes"
+	"context"
+	"field-service/common/auth"
+	"field-service/common/imaging"
+	"field-service/common/metrics"
+	"field-service/common/storage"
+	"field-service/common/util"
+	"field-service/config"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	errField "field-service/constants/error/field"
+	"field-service/domain/dto"
+	"field-service/domain/models"
+	"field-service/repositories"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+type FieldService struct {
+	repository repositories.IRepositoryRegistry
+	storage    storage.IStorage
+}
+
+type IFieldService interface {
+	GetAllWithPagination(context.Context, *dto.FieldRequestParam) (*util.PaginationResult, error)
+	GetAllWithoutPagination(context.Context) ([]dto.FieldResponse, error)
+	Search(context.Context, *dto.FieldSearchRequestParam) (*util.PaginationResult, error)
+	GetByUUID(context.Context, string) (*dto.FieldResponse, error)
+	Create(context.Context, *dto.FieldRequest) (*dto.FieldResponse, error)
+	Update(context.Context, string, *dto.UpdateFieldRequest) (*dto.FieldResponse, error)
+	Delete(context.Context, string) error
+	AddImages(context.Context, string, *dto.AddFieldImagesRequest) (*dto.FieldResponse, error)
+	RemoveImage(context.Context, string, *dto.RemoveFieldImageRequest) (*dto.FieldResponse, error)
+	ReorderImages(context.Context, string, *dto.ReorderFieldImagesRequest) (*dto.FieldResponse, error)
+	SetCoverImage(context.Context, string, *dto.SetCoverFieldImageRequest) (*dto.FieldResponse, error)
+	PresignImageUpload(context.Context, string, *dto.PresignFieldImageRequest) (*dto.PresignFieldImageResponse, error)
+	ConfirmImageUpload(context.Context, string, *dto.ConfirmFieldImageRequest) (*dto.FieldResponse, error)
+	DeleteOrphanedImages(context.Context, *dto.DeleteOrphanedImagesRequest) (*dto.DeleteOrphanedImagesResult, error)
+}
+
+const (
+	// presignExpiry is how long a presigned upload URL stays valid.
+	presignExpiry = 15 * time.Minute
+
+	defaultUploadConcurrency = 4
+	defaultSignedURLExpiry   = time.Hour
+
+	// imagePrefix is where every field image is stored.
+	imagePrefix = "images/"
+)
+
+func NewFieldService(repository repositories.IRepositoryRegistry, storage storage.IStorage) IFieldService {
+	return &FieldService{
+		repository: repository,
+		storage:    storage,
+	}
+}
+
+func (s *FieldService) getAllWithCursor(ctx context.Context, param *dto.FieldRequestParam) (*util.PaginationResult, error) {
+	// Cursor pages are always newest first.
+	if param.Sort != nil || param.SortColumn != nil || param.SortOrder != nil {
+		return nil, errConstant.ErrCursorWithSort
+	}
+
+	after, err := util.DecodeCursor(*param.Cursor)
+	if err != nil {
+		return nil, errConstant.ErrInvalidCursor
+	}
+
+	fields, nextCursor, err := s.repository.GetField().FindAllWithCursor(ctx, param, after)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldResults := make([]*dto.FieldResponse, 0, len(fields))
+	for _, field := range fields {
+		fieldResults = append(fieldResults, s.toFieldResponse(ctx, &field))
+	}
+
+	response, err := util.GenerateCursorPagination(util.CursorPaginationParam{
+		Limit:      param.Limit,
+		NextCursor: nextCursor,
+		Data:       fieldResults,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *FieldService) GetAllWithPagination(ctx context.Context, param *dto.FieldRequestParam) (*util.PaginationResult, error) {
+	if param.Cursor != nil {
+		return s.getAllWithCursor(ctx, param)
+	}
+
+	fields, total, err := s.repository.GetField().FindAllWithPagination(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldResults := make([]*dto.FieldResponse, 0, len(fields))
+	for _, field := range fields {
+		fieldResults = append(fieldResults, s.toFieldResponse(ctx, &field))
+	}
+
+	pagination := &util.PaginationParam{
+		Count: total,
+		Page:  param.Page,
+		Limit: param.Limit,
+		Data:  fieldResults,
+	}
+
+	response := util.GeneratePagination(*pagination)
+	return &response, nil
+}
+
+func (s *FieldService) GetAllWithoutPagination(ctx context.Context) ([]dto.FieldResponse, error) {
+	fields, err := s.repository.GetField().FindAllWithoutPagination(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldResults := make([]dto.FieldResponse, 0, len(fields))
+	for _, field := range fields {
+		fieldResults = append(fieldResults, *s.toFieldResponse(ctx, &field))
+	}
+
+	return fieldResults, nil
+}
+
+func (s *FieldService) Search(ctx context.Context, param *dto.FieldSearchRequestParam) (*util.PaginationResult, error) {
+	fields, total, err := s.repository.GetField().Search(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldResults := make([]*dto.FieldResponse, 0, len(fields))
+	for _, field := range fields {
+		fieldResults = append(fieldResults, s.toFieldResponse(ctx, &field))
+	}
+
+	pagination := &util.PaginationParam{
+		Count: total,
+		Page:  param.Page,
+		Limit: param.Limit,
+		Data:  fieldResults,
+	}
+
+	response := util.GeneratePagination(*pagination)
+	return &response, nil
+}
+
+func (s *FieldService) GetByUUID(ctx context.Context, uuid string) (*dto.FieldResponse, error) {
+	field, err := s.repository.GetField().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toFieldResponse(ctx, field), nil
+}
+
+func (f *FieldService) validateUpload(images []multipart.FileHeader) error {
+	if images == nil || len(images) == 0 {
+		return errConstant.ErrInValidUploadFile
+	}
+
+	for _, image := range images {
+		if image.Size > 1024*1024*5 {
+			return errConstant.ErrSizeToBig
+		}
+	}
+
+	return nil
+}
+
+func storageDriver() string {
+	if config.Config.Storage.Driver == "" {
+		return storage.S3
+	}
+	return config.Config.Storage.Driver
+}
+
+func (s *FieldService) processAndUploadImage(ctx context.Context, image multipart.FileHeader) (string, []string, error) {
+	file, err := image.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	buffer := new(bytes.Buffer)
+	_, err = io.Copy(buffer, file)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return s.processAndUpload(ctx, buffer.Bytes())
+}
+
+// processAndUpload returns the stored value of the original and the keys of
+// every object it uploaded, including those uploaded before a failure.
+func (s *FieldService) processAndUpload(ctx context.Context, data []byte) (string, []string, error) {
+	outputs, err := imaging.Process(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Renditions of one image share a folder so the URL of the original is
+	// enough to find the others, see imaging.RenditionURLs.
+	folder := fmt.Sprintf("%s%s", imagePrefix, uuid.New())
+	var (
+		original string
+		keys     = make([]string, 0, len(outputs))
+	)
+	for _, output := range outputs {
+		filename := fmt.Sprintf("%s/%s%s", folder, output.Name, imaging.Extension)
+		start := time.Now()
+		_, err := s.storage.UploadFile(ctx, filename, imaging.ContentType, output.Data)
+		metrics.StorageUploadDuration.
+			WithLabelValues(storageDriver(), metrics.Result(err)).
+			Observe(time.Since(start).Seconds())
+		if err != nil {
+			return "", keys, err
+		}
+		keys = append(keys, filename)
+
+		if output.Name == imaging.Original {
+			original = s.storedImage(filename)
+		}
+	}
+	return original, keys, nil
+}
+
+// uploadImage processes and uploads images concurrently, keeping their order.
+// When any of them fails, everything already uploaded is deleted again. On
+// success it also returns the uploaded keys so the caller can roll them back
+// if saving the field fails.
+func (s *FieldService) uploadImage(ctx context.Context, images []multipart.FileHeader) ([]string, []string, error) {
+	err := s.validateUpload(images)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	concurrency := config.Config.Storage.UploadConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultUploadConcurrency
+	}
+
+	var (
+		mutex sync.Mutex
+		keys  []string
+		urls  = make([]string, len(images))
+	)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(concurrency)
+	for i, image := range images {
+		group.Go(func() error {
+			url, uploaded, err := s.processAndUploadImage(groupCtx, image)
+			mutex.Lock()
+			keys = append(keys, uploaded...)
+			mutex.Unlock()
+			if err != nil {
+				return err
+			}
+
+			urls[i] = url
+			return nil
+		})
+	}
+
+	err = group.Wait()
+	if err != nil {
+		s.deleteObjects(ctx, keys)
+		return nil, nil, err
+	}
+
+	return urls, keys, nil
+}
+
+// deleteObjects removes objects nothing references anymore. It outlives a
+// canceled request and only logs failures, which leave orphans behind.
+func (s *FieldService) deleteObjects(ctx context.Context, keys []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		err := s.storage.DeleteFile(ctx, key)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("failed to delete object %s: %v", key, err)
+		}
+	}
+}
+
+// fieldOwner resolves the owner a field is saved with. Only admins and calls
+// from other services may hand a field to someone else; fields an owner
+// creates are always their own.
+func fieldOwner(ctx context.Context, requested *string, current *uuid.UUID) (*uuid.UUID, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if ok && user.Role == constants.Owner && current == nil {
+		current = &user.UUID
+	}
+
+	if requested == nil {
+		return current, nil
+	}
+
+	owner, err := uuid.Parse(*requested)
+	if err != nil {
+		return nil, errField.ErrInvalidOwner
+	}
+
+	if ok && user.Role != constants.Admin && (current == nil || owner != *current) {
+		return nil, errConstant.ErrForbidden
+	}
+	return &owner, nil
+}
+
+// findOwnedField loads a field the user of ctx is about to change.
+func (s *FieldService) findOwnedField(ctx context.Context, uuid string) (*models.Field, error) {
+	field, err := s.repository.GetField().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = auth.Authorize(ctx, constants.FieldWrite, field.OwnerUUID)
+	if err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (s *FieldService) Create(ctx context.Context, request *dto.FieldRequest) (*dto.FieldResponse, error) {
+	owner, err := fieldOwner(ctx, request.OwnerUUID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = auth.Authorize(ctx, constants.FieldWrite, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	imageUrl, uploaded, err := s.uploadImage(ctx, request.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	field, err := s.repository.GetField().Create(ctx, &models.Field{
+		Code:         request.Code,
+		Name:         request.Name,
+		PricePerHour: request.PricePerHour,
+		Description:  request.Description,
+		Attributes:   request.Attributes,
+		Images:       imageUrl,
+		OwnerUUID:    owner,
+	})
+	if err != nil {
+		s.deleteObjects(ctx, uploaded)
+		return nil, err
+	}
+
+	return s.toFieldResponse(ctx, field), nil
+}
+
+func (s *FieldService) Update(ctx context.Context, uuidParams string, request *dto.UpdateFieldRequest) (*dto.FieldResponse, error) {
+	field, err := s.findOwnedField(ctx, uuidParams)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := fieldOwner(ctx, request.OwnerUUID, field.OwnerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		imageUrl []string
+		uploaded []string
+	)
+	if request.Images == nil {
+		imageUrl = field.Images
+	} else {
+		imageUrl, uploaded, err = s.uploadImage(ctx, request.Images)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fieldResult, err := s.repository.GetField().Update(ctx, uuidParams, &models.Field{
+		Code:         request.Code,
+		Name:         request.Name,
+		PricePerHour: request.PricePerHour,
+		Description:  request.Description,
+		Attributes:   request.Attributes,
+		Images:       imageUrl,
+		OwnerUUID:    owner,
+	})
+	if err != nil {
+		s.deleteObjects(ctx, uploaded)
+		return nil, err
+	}
+
+	fieldResult.UUID, _ = uuid.Parse(uuidParams)
+	return s.toFieldResponse(ctx, fieldResult), nil
+}
+
+func (s *FieldService) Delete(ctx context.Context, uuid string) error {
+	_, err := s.findOwnedField(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.GetField().Delete(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// storedImage is what Field.Images keeps for an uploaded object: its key when
+// storage.storeKeys is set, its URL otherwise.
+func (s *FieldService) storedImage(key string) string {
+	if config.Config.Storage.StoreKeys {
+		return key
+	}
+	return s.storage.ObjectURL(key)
+}
+
+// objectKey returns the storage key of an image given as a key or as any URL
+// handed out for it. The query string of a signed URL is ignored.
+func (s *FieldService) objectKey(image string) (string, bool) {
+	image, _, _ = strings.Cut(image, "?")
+	if !strings.Contains(image, "://") {
+		return image, image != ""
+	}
+
+	cdnBaseURL := strings.TrimSuffix(config.Config.Storage.CDNBaseURL, "/")
+	if key, ok := strings.CutPrefix(image, cdnBaseURL+"/"); ok && cdnBaseURL != "" {
+		return key, true
+	}
+	return s.storage.ObjectKey(image)
+}
+
+func (s *FieldService) sameImage(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	keyA, okA := s.objectKey(a)
+	keyB, okB := s.objectKey(b)
+	return okA && okB && keyA == keyB
+}
+
+// imageURL is the URL an image is handed out with, see storage.urlMode. URLs
+// outside the configured storage are returned unchanged.
+func (s *FieldService) imageURL(ctx context.Context, image string) string {
+	key, ok := s.objectKey(image)
+	if !ok {
+		return image
+	}
+
+	switch config.Config.Storage.URLMode {
+	case storage.URLSigned:
+		expiry := time.Duration(config.Config.Storage.SignedURLExpirySecond) * time.Second
+		if expiry <= 0 {
+			expiry = defaultSignedURLExpiry
+		}
+		url, err := s.storage.SignedURL(ctx, key, expiry)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("failed to sign image %s: %v", key, err)
+			return s.storage.ObjectURL(key)
+		}
+		return url
+	case storage.URLCDN:
+		return fmt.Sprintf("%s/%s", strings.TrimSuffix(config.Config.Storage.CDNBaseURL, "/"), key)
+	default:
+		return s.storage.ObjectURL(key)
+	}
+}
+
+func (s *FieldService) toFieldResponse(ctx context.Context, field *models.Field) *dto.FieldResponse {
+	images := make([]string, 0, len(field.Images))
+	renditions := make([]map[string]string, 0, len(field.Images))
+	for _, image := range field.Images {
+		urls := imaging.RenditionURLs(image)
+		for name, url := range urls {
+			urls[name] = s.imageURL(ctx, url)
+		}
+		images = append(images, urls[imaging.Original])
+		renditions = append(renditions, urls)
+	}
+
+	return &dto.FieldResponse{
+		UUID:            field.UUID,
+		Code:            field.Code,
+		Name:            field.Name,
+		PricePerHour:    field.PricePerHour,
+		Description:     field.Description,
+		Attributes:      field.Attributes,
+		Images:          images,
+		ImageRenditions: renditions,
+		OwnerUUID:       field.OwnerUUID,
+		CreatedAt:       field.CreatedAt,
+		UpdatedAt:       field.UpdatedAt,
+	}
+}
+
+// updateImages applies modify to the images the field has at the time of the
+// write rather than to the ones read by findOwnedField, which may be stale.
+func (s *FieldService) updateImages(
+	ctx context.Context,
+	field *models.Field,
+	modify func([]string) ([]string, error),
+) (*dto.FieldResponse, error) {
+	updated, err := s.repository.GetField().ModifyImages(ctx, field.UUID.String(), modify)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toFieldResponse(ctx, updated), nil
+}
+
+func (s *FieldService) AddImages(
+	ctx context.Context,
+	uuid string,
+	request *dto.AddFieldImagesRequest,
+) (*dto.FieldResponse, error) {
+	field, err := s.findOwnedField(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	imageUrl, uploaded, err := s.uploadImage(ctx, request.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.updateImages(ctx, field, func(current []string) ([]string, error) {
+		images := make([]string, 0, len(current)+len(imageUrl))
+		images = append(images, current...)
+		images = append(images, imageUrl...)
+		return images, nil
+	})
+	if err != nil {
+		s.deleteObjects(ctx, uploaded)
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *FieldService) RemoveImage(
+	ctx context.Context,
+	uuid string,
+	request *dto.RemoveFieldImageRequest,
+) (*dto.FieldResponse, error) {
+	field, err := s.findOwnedField(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed string
+	response, err := s.updateImages(ctx, field, func(current []string) ([]string, error) {
+		index := -1
+		for i, image := range current {
+			if (request.Index != nil && *request.Index == i) || (request.Index == nil && s.sameImage(request.URL, image)) {
+				index = i
+				break
+			}
+		}
+
+		if index < 0 {
+			return nil, errField.ErrFieldImageNotFound
+		}
+
+		removed = current[index]
+		images := make([]string, 0, len(current)-1)
+		images = append(images, current[:index]...)
+		images = append(images, current[index+1:]...)
+		return images, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The field no longer references the objects, so a failed delete only
+	// leaves an orphan behind; it must not fail the request.
+	keys := make([]string, 0)
+	for _, url := range imaging.RenditionURLs(removed) {
+		key, ok := s.objectKey(url)
+		if !ok {
+			logrus.WithContext(ctx).Warnf("image %s is not in the configured storage", url)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	s.deleteObjects(ctx, keys)
+
+	return response, nil
+}
+
+func (s *FieldService) ReorderImages(
+	ctx context.Context,
+	uuid string,
+	request *dto.ReorderFieldImagesRequest,
+) (*dto.FieldResponse, error) {
+	field, err := s.findOwnedField(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateImages(ctx, field, func(current []string) ([]string, error) {
+		if len(request.Order) != len(current) {
+			return nil, errField.ErrInvalidImageOrder
+		}
+
+		seen := make([]bool, len(current))
+		images := make([]string, 0, len(current))
+		for _, index := range request.Order {
+			if index < 0 || index >= len(current) || seen[index] {
+				return nil, errField.ErrInvalidImageOrder
+			}
+			seen[index] = true
+			images = append(images, current[index])
+		}
+		return images, nil
+	})
+}
+
+func (s *FieldService) SetCoverImage(
+	ctx context.Context,
+	uuid string,
+	request *dto.SetCoverFieldImageRequest,
+) (*dto.FieldResponse, error) {
+	field, err := s.findOwnedField(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateImages(ctx, field, func(current []string) ([]string, error) {
+		index := *request.Index
+		if index >= len(current) {
+			return nil, errField.ErrFieldImageNotFound
+		}
+
+		images := make([]string, 0, len(current))
+		images = append(images, current[index])
+		images = append(images, current[:index]...)
+		images = append(images, current[index+1:]...)
+		return images, nil
+	})
+}
+
+// uploadFolder is where presigned uploads of a field land. Confirm only
+// accepts keys under it, so a field cannot claim objects it did not upload.
+func uploadFolder(fieldUUID string) string {
+	return fmt.Sprintf("%suploads/%s/", imagePrefix, fieldUUID)
+}
+
+func (s *FieldService) PresignImageUpload(
+	ctx context.Context,
+	uuidParam string,
+	request *dto.PresignFieldImageRequest,
+) (*dto.PresignFieldImageResponse, error) {
+	field, err := s.findOwnedField(ctx, uuidParam)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s%s%s", uploadFolder(field.UUID.String()), uuid.New(), imaging.Types[request.ContentType])
+	upload, err := s.storage.PresignUpload(ctx, key, request.ContentType, request.Size, presignExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PresignFieldImageResponse{
+		Key:       key,
+		UploadURL: upload.URL,
+		Method:    upload.Method,
+		Headers:   upload.Headers,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+func (s *FieldService) ConfirmImageUpload(
+	ctx context.Context,
+	uuid string,
+	request *dto.ConfirmFieldImageRequest,
+) (*dto.FieldResponse, error) {
+	field, err := s.findOwnedField(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	name, ok := strings.CutPrefix(request.Key, uploadFolder(field.UUID.String()))
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return nil, errField.ErrInvalidUploadKey
+	}
+
+	exists, err := s.storage.Exists(ctx, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errField.ErrUploadNotFound
+	}
+
+	data, err := s.storage.ReadFile(ctx, request.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The upload went straight to storage, so it gets the checks and
+	// renditions of a multipart upload only now. Whatever fails them is
+	// deleted rather than left for the orphan cleanup.
+	original, uploaded, err := s.processAndUpload(ctx, data)
+	if err != nil {
+		s.deleteObjects(ctx, append(uploaded, request.Key))
+		return nil, err
+	}
+
+	response, err := s.updateImages(ctx, field, func(current []string) ([]string, error) {
+		images := make([]string, 0, len(current)+1)
+		images = append(images, current...)
+		images = append(images, original)
+		return images, nil
+	})
+	if err != nil {
+		s.deleteObjects(ctx, uploaded)
+		return nil, err
+	}
+
+	s.deleteObjects(ctx, []string{request.Key})
+	return response, nil
+}
+
+// DeleteOrphanedImages deletes, or only reports on a dry run, the objects
+// under the image prefix that no field references anymore.
+func (s *FieldService) DeleteOrphanedImages(
+	ctx context.Context,
+	request *dto.DeleteOrphanedImagesRequest,
+) (*dto.DeleteOrphanedImagesResult, error) {
+	images, err := s.repository.GetField().FindAllImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool)
+	unresolved := make([]string, 0)
+	for _, image := range images {
+		key, ok := s.objectKey(image)
+		if !ok {
+			unresolved = append(unresolved, image)
+			continue
+		}
+
+		referenced[key] = true
+		for _, url := range imaging.RenditionURLs(key) {
+			referenced[url] = true
+		}
+	}
+
+	result := &dto.DeleteOrphanedImagesResult{
+		Orphaned:   make([]string, 0),
+		Unresolved: unresolved,
+	}
+	if len(unresolved) > 0 && !request.DryRun && !request.Force {
+		return result, errField.ErrUnresolvedImages
+	}
+
+	objects, err := s.storage.List(ctx, imagePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-request.GracePeriod)
+	result.Scanned = len(objects)
+	for _, object := range objects {
+		if referenced[object.Key] || object.UpdatedAt.After(cutoff) {
+			continue
+		}
+		result.Orphaned = append(result.Orphaned, object.Key)
+
+		if request.DryRun {
+			continue
+		}
+
+		err = s.storage.DeleteFile(ctx, object.Key)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("failed to delete object %s: %v", object.Key, err)
+			continue
+		}
+		result.Deleted++
+	}
+
+	return result, nil
+}
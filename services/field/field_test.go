@@ -0,0 +1,592 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"field-service/common/imaging"
+	"field-service/common/local"
+	"field-service/common/storage"
+	"field-service/config"
+	errConstant "field-service/constants/error"
+	errField "field-service/constants/error/field"
+	"field-service/domain/dto"
+	"field-service/domain/models"
+	"field-service/repositories"
+	fieldRepository "field-service/repositories/field"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const testBaseURL = "http://localhost/storage"
+
+// fakeFieldRepository keeps a single field in memory. Methods the tests do not
+// need are left to the embedded nil interface.
+type fakeFieldRepository struct {
+	fieldRepository.IFieldRepository
+	field     *models.Field
+	modifyErr error
+}
+
+func (r *fakeFieldRepository) FindByUUID(_ context.Context, uuid string) (*models.Field, error) {
+	if r.field.UUID.String() != uuid {
+		return nil, errField.ErrFieldNotFound
+	}
+
+	field := *r.field
+	field.Images = slices.Clone(r.field.Images)
+	return &field, nil
+}
+
+func (r *fakeFieldRepository) ModifyImages(
+	ctx context.Context,
+	uuid string,
+	modify func([]string) ([]string, error),
+) (*models.Field, error) {
+	if r.modifyErr != nil {
+		return nil, r.modifyErr
+	}
+
+	field, err := r.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := modify(field.Images)
+	if err != nil {
+		return nil, err
+	}
+	r.field.Images = pq.StringArray(images)
+	return r.FindByUUID(ctx, uuid)
+}
+
+type fakeRepositoryRegistry struct {
+	repositories.IRepositoryRegistry
+	field *fakeFieldRepository
+}
+
+func (r *fakeRepositoryRegistry) GetField() fieldRepository.IFieldRepository {
+	return r.field
+}
+
+// newTestService serves a field with images from a local storage in a
+// temporary directory. The context carries no user, as calls from other
+// services do, so ownership does not get in the way.
+func newTestService(t *testing.T, images ...string) (*FieldService, *fakeFieldRepository, *local.LocalClient) {
+	t.Helper()
+	previous := config.Config
+	t.Cleanup(func() { config.Config = previous })
+	config.Config.Storage = config.Storage{}
+
+	storage := local.NewLocalClient(t.TempDir(), testBaseURL, "secret").(*local.LocalClient)
+	repository := &fakeFieldRepository{
+		field: &models.Field{UUID: uuid.New(), Images: pq.StringArray(images)},
+	}
+	service := NewFieldService(&fakeRepositoryRegistry{field: repository}, storage).(*FieldService)
+	return service, repository, storage
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	img.Set(0, 0, color.RGBA{R: 0xFF, A: 0xFF})
+
+	buffer := new(bytes.Buffer)
+	err := png.Encode(buffer, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// fileHeaders turns files into the headers of a parsed multipart form.
+func fileHeaders(t *testing.T, files ...[]byte) []multipart.FileHeader {
+	t.Helper()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for i, file := range files {
+		part, err := writer.CreateFormFile("images", fmt.Sprintf("image%d.png", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = part.Write(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(32 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = form.RemoveAll() })
+
+	headers := make([]multipart.FileHeader, 0, len(files))
+	for _, header := range form.File["images"] {
+		headers = append(headers, *header)
+	}
+	return headers
+}
+
+// storedKeys lists every object in storage, sorted.
+func storedKeys(t *testing.T, storage *local.LocalClient) []string {
+	t.Helper()
+	objects, err := storage.List(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// storeRenditions puts every rendition of the image in folder into storage and
+// returns the URL of its original.
+func storeRenditions(t *testing.T, storage *local.LocalClient, folder string) string {
+	t.Helper()
+	for _, rendition := range imaging.Renditions {
+		_, err := storage.UploadFile(context.Background(), folder+"/"+rendition.Name+imaging.Extension, imaging.ContentType, []byte("image"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return storage.ObjectURL(folder + "/" + imaging.Original + imaging.Extension)
+}
+
+func TestAddImages(t *testing.T) {
+	existing := testBaseURL + "/images/existing/original.jpg"
+	service, repository, storage := newTestService(t, existing)
+
+	response, err := service.AddImages(context.Background(), repository.field.UUID.String(), &dto.AddFieldImagesRequest{
+		Images: fileHeaders(t, pngImage(t, 40, 20), pngImage(t, 20, 40)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	images := repository.field.Images
+	if len(images) != 3 || images[0] != existing {
+		t.Fatalf("got images %v, want the existing one followed by two new ones", images)
+	}
+	if !reflect.DeepEqual(response.Images, []string(images)) {
+		t.Errorf("got response images %v, want %v", response.Images, images)
+	}
+
+	keys := storedKeys(t, storage)
+	if len(keys) != 2*len(imaging.Renditions) {
+		t.Fatalf("got stored objects %v, want every rendition of both images", keys)
+	}
+	for _, image := range images[1:] {
+		for name, url := range imaging.RenditionURLs(image) {
+			key, _ := strings.CutPrefix(url, testBaseURL+"/")
+			if !slices.Contains(keys, key) {
+				t.Errorf("rendition %s of %s was not stored", name, image)
+			}
+		}
+	}
+}
+
+func TestRemoveImage(t *testing.T) {
+	index := func(i int) *int { return &i }
+
+	tests := []struct {
+		name        string
+		request     dto.RemoveFieldImageRequest
+		wantErr     error
+		wantRemoved string
+	}{
+		{name: "by index", request: dto.RemoveFieldImageRequest{Index: index(0)}, wantRemoved: "a"},
+		{name: "by url", request: dto.RemoveFieldImageRequest{URL: testBaseURL + "/images/b/original.jpg"}, wantRemoved: "b"},
+		{
+			name:        "by signed url",
+			request:     dto.RemoveFieldImageRequest{URL: testBaseURL + "/images/b/original.jpg?expires=1&signature=x"},
+			wantRemoved: "b",
+		},
+		{name: "by key", request: dto.RemoveFieldImageRequest{URL: "images/b/original.jpg"}, wantRemoved: "b"},
+		{name: "index out of range", request: dto.RemoveFieldImageRequest{Index: index(2)}, wantErr: errField.ErrFieldImageNotFound},
+		{
+			name:    "unknown url",
+			request: dto.RemoveFieldImageRequest{URL: testBaseURL + "/images/c/original.jpg"},
+			wantErr: errField.ErrFieldImageNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, repository, storage := newTestService(t)
+			repository.field.Images = pq.StringArray{
+				storeRenditions(t, storage, "images/a"),
+				storeRenditions(t, storage, "images/b"),
+			}
+			before := slices.Clone(repository.field.Images)
+
+			_, err := service.RemoveImage(context.Background(), repository.field.UUID.String(), &test.request)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got %v, want %v", err, test.wantErr)
+				}
+				if !reflect.DeepEqual(repository.field.Images, before) {
+					t.Errorf("got images %v, want them unchanged", repository.field.Images)
+				}
+				if keys := storedKeys(t, storage); len(keys) != 2*len(imaging.Renditions) {
+					t.Errorf("got stored objects %v, want nothing deleted", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, image := range repository.field.Images {
+				if strings.Contains(image, "/images/"+test.wantRemoved+"/") {
+					t.Errorf("got images %v, want %s removed", repository.field.Images, test.wantRemoved)
+				}
+			}
+			keys := storedKeys(t, storage)
+			if len(keys) != len(imaging.Renditions) {
+				t.Fatalf("got stored objects %v, want one image left", keys)
+			}
+			for _, key := range keys {
+				if strings.HasPrefix(key, "images/"+test.wantRemoved+"/") {
+					t.Errorf("rendition %s of the removed image is still stored", key)
+				}
+			}
+		})
+	}
+}
+
+func TestReorderImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []int
+		want    []string
+		wantErr bool
+	}{
+		{name: "reversed", order: []int{2, 1, 0}, want: []string{"c", "b", "a"}},
+		{name: "unchanged", order: []int{0, 1, 2}, want: []string{"a", "b", "c"}},
+		{name: "too short", order: []int{1, 0}, wantErr: true},
+		{name: "too long", order: []int{0, 1, 2, 3}, wantErr: true},
+		{name: "repeated", order: []int{0, 0, 1}, wantErr: true},
+		{name: "out of range", order: []int{0, 1, 3}, wantErr: true},
+		{name: "negative", order: []int{-1, 0, 1}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, repository, _ := newTestService(t, "a", "b", "c")
+
+			_, err := service.ReorderImages(context.Background(), repository.field.UUID.String(), &dto.ReorderFieldImagesRequest{
+				Order: test.order,
+			})
+			if test.wantErr {
+				if !errors.Is(err, errField.ErrInvalidImageOrder) {
+					t.Fatalf("got %v, want ErrInvalidImageOrder", err)
+				}
+				test.want = []string{"a", "b", "c"}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual([]string(repository.field.Images), test.want) {
+				t.Errorf("got images %v, want %v", repository.field.Images, test.want)
+			}
+		})
+	}
+}
+
+func TestSetCoverImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		index   int
+		want    []string
+		wantErr bool
+	}{
+		{name: "last", index: 2, want: []string{"c", "a", "b"}},
+		{name: "middle", index: 1, want: []string{"b", "a", "c"}},
+		{name: "already cover", index: 0, want: []string{"a", "b", "c"}},
+		{name: "out of range", index: 3, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, repository, _ := newTestService(t, "a", "b", "c")
+
+			_, err := service.SetCoverImage(context.Background(), repository.field.UUID.String(), &dto.SetCoverFieldImageRequest{
+				Index: &test.index,
+			})
+			if test.wantErr {
+				if !errors.Is(err, errField.ErrFieldImageNotFound) {
+					t.Fatalf("got %v, want ErrFieldImageNotFound", err)
+				}
+				test.want = []string{"a", "b", "c"}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual([]string(repository.field.Images), test.want) {
+				t.Errorf("got images %v, want %v", repository.field.Images, test.want)
+			}
+		})
+	}
+}
+
+func TestConfirmImageUpload(t *testing.T) {
+	errModify := errors.New("modify failed")
+
+	tests := []struct {
+		name      string
+		key       func(folder string) string
+		data      []byte
+		modifyErr error
+		wantErr   error
+		wantKept  bool
+	}{
+		{
+			name: "valid upload",
+			key:  func(folder string) string { return folder + "photo.png" },
+			data: pngImage(t, 40, 20),
+		},
+		{
+			name:     "another field's folder",
+			key:      func(string) string { return uploadFolder(uuid.NewString()) + "photo.png" },
+			data:     pngImage(t, 40, 20),
+			wantErr:  errField.ErrInvalidUploadKey,
+			wantKept: true,
+		},
+		{
+			name:     "nested key",
+			key:      func(folder string) string { return folder + "nested/photo.png" },
+			data:     pngImage(t, 40, 20),
+			wantErr:  errField.ErrInvalidUploadKey,
+			wantKept: true,
+		},
+		{
+			name:    "folder only",
+			key:     func(folder string) string { return folder },
+			wantErr: errField.ErrInvalidUploadKey,
+		},
+		{
+			name:    "not uploaded",
+			key:     func(folder string) string { return folder + "photo.png" },
+			wantErr: errField.ErrUploadNotFound,
+		},
+		{
+			name:    "not an image",
+			key:     func(folder string) string { return folder + "photo.png" },
+			data:    []byte("not an image at all"),
+			wantErr: errConstant.ErrUnsupportedImage,
+		},
+		{
+			name:      "field update fails",
+			key:       func(folder string) string { return folder + "photo.png" },
+			data:      pngImage(t, 40, 20),
+			modifyErr: errModify,
+			wantErr:   errModify,
+			wantKept:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			existing := testBaseURL + "/images/existing/original.jpg"
+			service, repository, storage := newTestService(t, existing)
+			repository.modifyErr = test.modifyErr
+
+			key := test.key(uploadFolder(repository.field.UUID.String()))
+			if test.data != nil {
+				_, err := storage.UploadFile(context.Background(), key, "image/png", test.data)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := service.ConfirmImageUpload(context.Background(), repository.field.UUID.String(), &dto.ConfirmFieldImageRequest{
+				Key: key,
+			})
+			keys := storedKeys(t, storage)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got %v, want %v", err, test.wantErr)
+				}
+				if !reflect.DeepEqual([]string(repository.field.Images), []string{existing}) {
+					t.Errorf("got images %v, want them unchanged", repository.field.Images)
+				}
+
+				want := []string{}
+				if test.wantKept {
+					want = []string{key}
+				}
+				if !reflect.DeepEqual(keys, want) {
+					t.Errorf("got stored objects %v, want %v", keys, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			images := repository.field.Images
+			if len(images) != 2 || images[0] != existing {
+				t.Fatalf("got images %v, want the existing one followed by the upload", images)
+			}
+			if slices.Contains(keys, key) {
+				t.Errorf("the raw upload %s is still stored", key)
+			}
+			if len(keys) != len(imaging.Renditions) {
+				t.Fatalf("got stored objects %v, want the renditions only", keys)
+			}
+			for name, url := range imaging.RenditionURLs(images[1]) {
+				key, _ := strings.CutPrefix(url, testBaseURL+"/")
+				if !slices.Contains(keys, key) {
+					t.Errorf("rendition %s of %s was not stored", name, images[1])
+				}
+			}
+		})
+	}
+}
+
+func TestImageURLSignedExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		second int
+		want   time.Duration
+	}{
+		{name: "configured", second: 60, want: time.Minute},
+		{name: "unset", second: 0, want: defaultSignedURLExpiry},
+		{name: "negative", second: -5, want: defaultSignedURLExpiry},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, _, _ := newTestService(t)
+			config.Config.Storage.URLMode = storage.URLSigned
+			config.Config.Storage.SignedURLExpirySecond = test.second
+
+			signed, err := url.Parse(service.imageURL(context.Background(), "images/a/original.jpg"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			expires, err := strconv.ParseInt(signed.Query().Get("expires"), 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := time.Until(time.Unix(expires, 0))
+			if got <= test.want-5*time.Second || got > test.want {
+				t.Errorf("got a URL valid for %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAddImagesKeepsOrder(t *testing.T) {
+	service, repository, storage := newTestService(t)
+	config.Config.Storage.UploadConcurrency = 2
+
+	widths := []int{60, 10, 50, 20, 40, 30}
+	files := make([][]byte, 0, len(widths))
+	for _, width := range widths {
+		files = append(files, pngImage(t, width, 10))
+	}
+
+	_, err := service.AddImages(context.Background(), repository.field.UUID.String(), &dto.AddFieldImagesRequest{
+		Images: fileHeaders(t, files...),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repository.field.Images) != len(widths) {
+		t.Fatalf("got images %v, want %d", repository.field.Images, len(widths))
+	}
+	for i, stored := range repository.field.Images {
+		key, _ := strings.CutPrefix(stored, testBaseURL+"/")
+		data, err := storage.ReadFile(context.Background(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size.Width != widths[i] {
+			t.Errorf("got image %d %d wide, want %d", i, size.Width, widths[i])
+		}
+	}
+}
+
+func TestAddImagesRollsBack(t *testing.T) {
+	errModify := errors.New("modify failed")
+
+	tests := []struct {
+		name      string
+		files     func(t *testing.T) [][]byte
+		modifyErr error
+		wantErr   error
+	}{
+		{
+			name: "one invalid image",
+			files: func(t *testing.T) [][]byte {
+				return [][]byte{pngImage(t, 40, 20), []byte("not an image at all"), pngImage(t, 20, 40)}
+			},
+			wantErr: errConstant.ErrUnsupportedImage,
+		},
+		{
+			name: "one truncated image",
+			files: func(t *testing.T) [][]byte {
+				valid := pngImage(t, 40, 20)
+				return [][]byte{valid, valid[:len(valid)/2], pngImage(t, 20, 40)}
+			},
+			wantErr: errConstant.ErrInValidUploadFile,
+		},
+		{
+			name: "field update fails",
+			files: func(t *testing.T) [][]byte {
+				return [][]byte{pngImage(t, 40, 20), pngImage(t, 20, 40)}
+			},
+			modifyErr: errModify,
+			wantErr:   errModify,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			existing := testBaseURL + "/images/existing/original.jpg"
+			service, repository, storage := newTestService(t, existing)
+			repository.modifyErr = test.modifyErr
+
+			_, err := service.AddImages(context.Background(), repository.field.UUID.String(), &dto.AddFieldImagesRequest{
+				Images: fileHeaders(t, test.files(t)...),
+			})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("got %v, want %v", err, test.wantErr)
+			}
+
+			if !reflect.DeepEqual([]string(repository.field.Images), []string{existing}) {
+				t.Errorf("got images %v, want them unchanged", repository.field.Images)
+			}
+			if keys := storedKeys(t, storage); len(keys) != 0 {
+				t.Errorf("got stored objects %v, want every upload deleted", keys)
+			}
+		})
+	}
+}
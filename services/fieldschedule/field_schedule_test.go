@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"field-service/clients/user"
+	"field-service/constants"
+	errConstant "field-service/constants/error"
+	"field-service/domain/dto"
+	"field-service/domain/models"
+	"field-service/repositories"
+	fieldScheduleRepository "field-service/repositories/fieldschedule"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeFieldScheduleRepository books every schedule it is asked for. Methods
+// the tests do not need are left to the embedded nil interface.
+type fakeFieldScheduleRepository struct {
+	fieldScheduleRepository.IFieldScheduleRepository
+	booked []string
+}
+
+func (r *fakeFieldScheduleRepository) FindByUUID(context.Context, string) (*models.FieldSchedule, error) {
+	return &models.FieldSchedule{}, nil
+}
+
+func (r *fakeFieldScheduleRepository) UpdateStatus(
+	_ context.Context,
+	_ constants.FieldScheduleStatus,
+	uuid string,
+) (bool, error) {
+	r.booked = append(r.booked, uuid)
+	return true, nil
+}
+
+type fakeRepositoryRegistry struct {
+	repositories.IRepositoryRegistry
+	fieldSchedule *fakeFieldScheduleRepository
+}
+
+func (r *fakeRepositoryRegistry) GetFieldSchedule() fieldScheduleRepository.IFieldScheduleRepository {
+	return r.fieldSchedule
+}
+
+func TestUpdateStatusAuthorization(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    *clients.UserData
+		wantErr error
+	}{
+		{name: "order service"},
+		{name: "customer", user: &clients.UserData{UUID: uuid.New(), Role: constants.Customer}},
+		{name: "admin", user: &clients.UserData{UUID: uuid.New(), Role: constants.Admin}},
+		{name: "owner", user: &clients.UserData{UUID: uuid.New(), Role: constants.Owner}, wantErr: errConstant.ErrForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &fakeFieldScheduleRepository{}
+			service := NewFieldScheduleService(&fakeRepositoryRegistry{fieldSchedule: repository})
+
+			ctx := context.Background()
+			if test.user != nil {
+				ctx = context.WithValue(ctx, constants.User, test.user)
+			}
+
+			scheduleID := uuid.NewString()
+			err := service.UpdateStatus(ctx, &dto.UpdateStatusFieldScheduleRequest{FieldScheduleIDs: []string{scheduleID}})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("got %v, want %v", err, test.wantErr)
+			}
+
+			wantBooked := 1
+			if test.wantErr != nil {
+				wantBooked = 0
+			}
+			if len(repository.booked) != wantBooked {
+				t.Errorf("got booked %v, want %d schedules", repository.booked, wantBooked)
+			}
+		})
+	}
+}
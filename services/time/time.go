@@ -1,85 +1,85 @@
-package services
-
-import (
-	"context"
-	"field-service/domain/dto"
-	"field-service/domain/models"
-	"field-service/repositories"
-)
-
-type TimeService struct {
-	repository repositories.IRepositoryRegistry
-}
-
-type ITimeService interface {
-	GetAll(context.Context) ([]dto.TimeResponse, error)
-	GetByUUID(context.Context, string) (*dto.TimeResponse, error)
-	Create(context.Context, *dto.TimeRequest) (*dto.TimeResponse, error)
-}
-
-func NewTimeService(repository repositories.IRepositoryRegistry) ITimeService {
-	return &TimeService{
-		repository: repository,
-	}
-}
-
-func (s *TimeService) GetAll(ctx context.Context) ([]dto.TimeResponse, error) {
-	times, err := s.repository.GetTime().FindAll(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	timeResults := make([]dto.TimeResponse, 0, len(times))
-	for _, time := range times {
-		timeResults = append(timeResults, dto.TimeResponse{
-			UUID:      time.UUID,
-			StartTime: time.StartTime,
-			EndTime:   time.EndTime,
-			CreatedAt: time.CreatedAt,
-			UpdatedAt: time.UpdatedAt,
-		})
-	}
-
-	return timeResults, nil
-}
-
-func (s *TimeService) GetByUUID(ctx context.Context, uuid string) (*dto.TimeResponse, error) {
-	time, err := s.repository.GetTime().FindByUUID(ctx, uuid)
-	if err != nil {
-		return nil, err
-	}
-
-	timeResult := dto.TimeResponse{
-		UUID:      time.UUID,
-		StartTime: time.StartTime,
-		EndTime:   time.EndTime,
-		CreatedAt: time.CreatedAt,
-		UpdatedAt: time.UpdatedAt,
-	}
-
-	return &timeResult, nil
-}
-
-func (s *TimeService) Create(ctx context.Context, request *dto.TimeRequest) (*dto.TimeResponse, error) {
-	time := &dto.TimeRequest{
-		StartTime: request.StartTime,
-		EndTime:   request.EndTime,
-	}
-
-	timeResult, err := s.repository.GetTime().Create(ctx, &models.Time{
-		StartTime: time.StartTime,
-		EndTime:   time.EndTime,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	response := dto.TimeResponse{
-		UUID:      timeResult.UUID,
-		StartTime: timeResult.StartTime,
-		EndTime:   timeResult.EndTime,
-		CreatedAt: timeResult.CreatedAt,
-		UpdatedAt: timeResult.UpdatedAt,
-	}
-	return &response, nil
-}
+package services
+
+import (
+	"context"
+	"field-service/domain/dto"
+	"field-service/domain/models"
+	"field-service/repositories"
+)
+
+type TimeService struct {
+	repository repositories.IRepositoryRegistry
+}
+
+type ITimeService interface {
+	GetAll(context.Context) ([]dto.TimeResponse, error)
+	GetByUUID(context.Context, string) (*dto.TimeResponse, error)
+	Create(context.Context, *dto.TimeRequest) (*dto.TimeResponse, error)
+}
+
+func NewTimeService(repository repositories.IRepositoryRegistry) ITimeService {
+	return &TimeService{
+		repository: repository,
+	}
+}
+
+func (s *TimeService) GetAll(ctx context.Context) ([]dto.TimeResponse, error) {
+	times, err := s.repository.GetTime().FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timeResults := make([]dto.TimeResponse, 0, len(times))
+	for _, time := range times {
+		timeResults = append(timeResults, dto.TimeResponse{
+			UUID:      time.UUID,
+			StartTime: time.StartTime,
+			EndTime:   time.EndTime,
+			CreatedAt: time.CreatedAt,
+			UpdatedAt: time.UpdatedAt,
+		})
+	}
+
+	return timeResults, nil
+}
+
+func (s *TimeService) GetByUUID(ctx context.Context, uuid string) (*dto.TimeResponse, error) {
+	time, err := s.repository.GetTime().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	timeResult := dto.TimeResponse{
+		UUID:      time.UUID,
+		StartTime: time.StartTime,
+		EndTime:   time.EndTime,
+		CreatedAt: time.CreatedAt,
+		UpdatedAt: time.UpdatedAt,
+	}
+
+	return &timeResult, nil
+}
+
+func (s *TimeService) Create(ctx context.Context, request *dto.TimeRequest) (*dto.TimeResponse, error) {
+	time := &dto.TimeRequest{
+		StartTime: request.StartTime,
+		EndTime:   request.EndTime,
+	}
+
+	timeResult, err := s.repository.GetTime().Create(ctx, &models.Time{
+		StartTime: time.StartTime,
+		EndTime:   time.EndTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.TimeResponse{
+		UUID:      timeResult.UUID,
+		StartTime: timeResult.StartTime,
+		EndTime:   timeResult.EndTime,
+		CreatedAt: timeResult.CreatedAt,
+		UpdatedAt: timeResult.UpdatedAt,
+	}
+	return &response, nil
+}